@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SafetyLimits bound the changes remediation actions may make
+type SafetyLimits struct {
+	// MaxScaleFactor is how many times its current replicas a target may be scaled to
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxScaleFactor *int32 `json:"maxScaleFactor,omitempty"`
+
+	// MinPods is the fewest replicas a target may be scaled to
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinPods *int32 `json:"minPods,omitempty"`
+
+	// MaxScalingDuration is the longest a temporary scaling change may last
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +optional
+	MaxScalingDuration string `json:"maxScalingDuration,omitempty"`
+
+	// MaxActionsPerHour limits how many remediation actions may be taken per hour across
+	// the cluster. It is not overridden per namespace.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxActionsPerHour *int32 `json:"maxActionsPerHour,omitempty"`
+
+	// MaxActionsPerHourPerNamespace limits the remediation actions per hour in each namespace
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxActionsPerHourPerNamespace *int32 `json:"maxActionsPerHourPerNamespace,omitempty"`
+
+	// MaxActionsPerHourPerPolicy limits the remediation actions per hour of each policy
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxActionsPerHourPerPolicy *int32 `json:"maxActionsPerHourPerPolicy,omitempty"`
+
+	// MaxActionsPerHourPerTarget limits the remediation actions per hour on each target resource
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxActionsPerHourPerTarget *int32 `json:"maxActionsPerHourPerTarget,omitempty"`
+}
+
+// NamespaceOverride adjusts the settings for a single namespace
+type NamespaceOverride struct {
+	// Namespace the override applies to
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// SafetyLimits set here replace the cluster-wide ones in the namespace
+	// +optional
+	SafetyLimits SafetyLimits `json:"safetyLimits,omitempty"`
+
+	// ProtectedSelectors select additional resources in the namespace that remediation
+	// actions may not change
+	// +optional
+	ProtectedSelectors []metav1.LabelSelector `json:"protectedSelectors,omitempty"`
+}
+
+// PolicyDefaults are filled into policies that leave the settings unset when they are admitted
+type PolicyDefaults struct {
+	// CooldownPeriod of policies without one
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +optional
+	CooldownPeriod string `json:"cooldownPeriod,omitempty"`
+
+	// RevertStrategy of scaling actions without one (Gradual or Immediate)
+	// +kubebuilder:validation:Enum=Gradual;Immediate
+	// +optional
+	RevertStrategy string `json:"revertStrategy,omitempty"`
+
+	// ConflictResolution of actions without one
+	// +optional
+	ConflictResolution string `json:"conflictResolution,omitempty"`
+}
+
+// KubeMedicConfigSpec defines the installation-wide settings of KubeMedic
+type KubeMedicConfigSpec struct {
+	// SafetyLimits bound the changes remediation actions may make
+	// +optional
+	SafetyLimits SafetyLimits `json:"safetyLimits,omitempty"`
+
+	// DeniedNamespaces may not hold remediation policies. When unset, the system
+	// namespaces kube-system, kube-public, kube-node-lease, cert-manager and
+	// ingress-nginx are denied.
+	// +optional
+	DeniedNamespaces []string `json:"deniedNamespaces,omitempty"`
+
+	// AllowedNamespaces, when set, are the only namespaces that may hold remediation policies
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// ProtectedSelectors select resources that remediation actions may not change, in
+	// addition to those labeled kubemedic.io/protected=true
+	// +optional
+	ProtectedSelectors []metav1.LabelSelector `json:"protectedSelectors,omitempty"`
+
+	// NamespaceOverrides adjust the settings for individual namespaces
+	// +optional
+	NamespaceOverrides []NamespaceOverride `json:"namespaceOverrides,omitempty"`
+
+	// PolicyDefaults are filled into policies when they are admitted
+	// +optional
+	PolicyDefaults PolicyDefaults `json:"policyDefaults,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KubeMedicConfig is the Schema for the kubemedicconfigs API. The one named "default"
+// configures the safety limits and protected resources of the installation.
+type KubeMedicConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KubeMedicConfigSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KubeMedicConfigList contains a list of KubeMedicConfig
+type KubeMedicConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubeMedicConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubeMedicConfig{}, &KubeMedicConfigList{})
+}
@@ -30,6 +30,11 @@ type RemediationBackupSpec struct {
 
 	// Annotations from the original resource
 	OriginalAnnotations map[string]string `json:"originalAnnotations,omitempty"`
+
+	// Fields of the resource changed by the remediation action, such as spec.replicas.
+	// A restore expects these to differ from the original state.
+	// +optional
+	ModifiedPaths []string `json:"modifiedPaths,omitempty"`
 }
 
 // ResourceReference contains information to find a Kubernetes resource
@@ -0,0 +1,91 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Restore phases
+const (
+	// RestoreSucceeded means the original state was applied to the resource
+	RestoreSucceeded = "Succeeded"
+	// RestoreConflict means fields were changed by others since the backup and the restore was not applied
+	RestoreConflict = "Conflict"
+	// RestoreFailed means the restore could not be applied
+	RestoreFailed = "Failed"
+)
+
+// RemediationRestoreSpec defines the desired state of RemediationRestore
+type RemediationRestoreSpec struct {
+	// Name of the RemediationBackup, in the same namespace, to restore
+	// +kubebuilder:validation:MinLength=1
+	BackupName string `json:"backupName"`
+
+	// Force applies the restore even when fields not changed by the remediation action
+	// have been changed since the backup, overwriting those changes
+	// +optional
+	Force bool `json:"force,omitempty"`
+}
+
+// RemediationRestoreStatus defines the observed state of RemediationRestore
+type RemediationRestoreStatus struct {
+	// Phase of the restore: Succeeded, Conflict or Failed
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Human readable result of the restore
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Fields changed by others since the backup
+	// +optional
+	Conflicts []string `json:"conflicts,omitempty"`
+
+	// Time the restore was completed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.backupName"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RemediationRestore is the Schema for the remediationrestores API. Creating one restores
+// the resource saved in a RemediationBackup to its original state, once.
+type RemediationRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RemediationRestoreSpec   `json:"spec,omitempty"`
+	Status RemediationRestoreStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RemediationRestoreList contains a list of RemediationRestore
+type RemediationRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RemediationRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RemediationRestore{}, &RemediationRestoreList{})
+}
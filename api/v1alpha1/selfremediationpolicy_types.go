@@ -28,6 +28,47 @@ const (
 	MemoryUsage ConditionType = "MemoryUsage"
 	ErrorRate   ConditionType = "ErrorRate"
 	PodRestarts ConditionType = "PodRestarts"
+	// CrashLoopBackOff, OOMKilled and ImagePullBackOff conditions count the pod's
+	// containers in that state
+	CrashLoopBackOff ConditionType = "CrashLoopBackOff"
+	OOMKilled        ConditionType = "OOMKilled"
+	ImagePullBackOff ConditionType = "ImagePullBackOff"
+	// PromQL conditions compare the result of a Prometheus query
+	PromQL ConditionType = "PromQL"
+)
+
+// Metrics source values for Condition.Source
+const (
+	SourceMetricsServer = "MetricsServer"
+	SourcePrometheus    = "Prometheus"
+	SourceCustom        = "Custom"
+	SourceExternal      = "External"
+)
+
+// RevertStrategy values for ScalingParameters.RevertStrategy
+const (
+	RevertImmediate = "Immediate"
+	RevertGradual   = "Gradual"
+)
+
+// Aggregation values for Condition.Aggregation
+const (
+	AggregateAverage            = "Average"
+	AggregateMax                = "Max"
+	AggregateP95                = "P95"
+	AggregateCountOverThreshold = "CountOverThreshold"
+)
+
+// RelativeTo values for Condition.RelativeTo
+const (
+	RelativeToRequests = "Requests"
+	RelativeToLimits   = "Limits"
+)
+
+// RestartStrategy values for RestartParameters.Strategy
+const (
+	RestartEvict          = "Evict"
+	RestartRollingRestart = "RollingRestart"
 )
 
 // ActionType defines the type of remediation action
@@ -47,12 +88,62 @@ type Condition struct {
 	// Type of condition to monitor
 	Type ConditionType `json:"type"`
 
-	// Threshold value as a string (e.g., "80%", "100m", "2")
+	// Threshold value as a string (e.g., "80%", "100m", "2"), optionally with a
+	// comparison operator (">=500m", "<2") or as a range ("60%..80%")
 	Threshold string `json:"threshold"`
 
 	// Duration the condition must be true before taking action
 	// +optional
 	Duration string `json:"duration,omitempty"`
+
+	// Container is the container whose usage CPUUsage and MemoryUsage conditions measure,
+	// such as the application rather than its sidecars; all containers are summed when empty
+	// +optional
+	Container string `json:"container,omitempty"`
+
+	// RelativeTo is what the percentage thresholds of CPUUsage and MemoryUsage conditions
+	// are of: the Requests or Limits of the measured containers. When unset, CPU is
+	// relative to requests and memory to limits, falling back to the other.
+	// +kubebuilder:validation:Enum=Requests;Limits
+	// +optional
+	RelativeTo string `json:"relativeTo,omitempty"`
+
+	// Window is the period PodRestarts conditions count restarts in and OOMKilled
+	// conditions count OOM kills in, such as "10m". PodRestarts conditions with a rate
+	// threshold default to its period; otherwise all restarts and OOM kills count.
+	// +optional
+	Window string `json:"window,omitempty"`
+
+	// Aggregation combines the values of the target's pods: Average (the default), Max,
+	// P95, or CountOverThreshold, the number of pods meeting the threshold
+	// +kubebuilder:validation:Enum=Average;Max;P95;CountOverThreshold
+	// +optional
+	Aggregation string `json:"aggregation,omitempty"`
+
+	// MinCount is how many pods must meet the threshold for a CountOverThreshold
+	// condition to be met, 1 when unset
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinCount *int32 `json:"minCount,omitempty"`
+
+	// Source of the metric: MetricsServer (the default for CPUUsage and MemoryUsage),
+	// Prometheus (the default for PromQL), or the Custom or External metrics APIs
+	// +kubebuilder:validation:Enum=MetricsServer;Prometheus;Custom;External
+	// +optional
+	Source string `json:"source,omitempty"`
+
+	// Query is the PromQL query of Prometheus conditions. It is a Go template of the
+	// pod's {{ .Namespace }} and {{ .Pod }}, and must return a single value.
+	// +optional
+	Query string `json:"query,omitempty"`
+
+	// Metric is the name of the metric of Custom and External conditions
+	// +optional
+	Metric string `json:"metric,omitempty"`
+
+	// MetricSelector selects the series of External metrics
+	// +optional
+	MetricSelector *metav1.LabelSelector `json:"metricSelector,omitempty"`
 }
 
 // Target defines the resource to apply remediation on
@@ -82,11 +173,104 @@ type ScalingParameters struct {
 	// +optional
 	RevertStrategy string `json:"revertStrategy,omitempty"`
 
+	// RevertStepSize is how many replicas a Gradual revert moves per step (default 1)
+	// +optional
+	RevertStepSize *int32 `json:"revertStepSize,omitempty"`
+
+	// RevertStepInterval is the time between Gradual revert steps (default "1m")
+	// +optional
+	RevertStepInterval string `json:"revertStepInterval,omitempty"`
+
 	// NotificationWebhook for sending scaling decisions
 	// +optional
 	NotificationWebhook string `json:"notificationWebhook,omitempty"`
 }
 
+// RestartParameters defines how RestartPod restarts pods
+type RestartParameters struct {
+	// Strategy is Evict to evict the target pod through the Eviction API, or
+	// RollingRestart to roll the pod's owning Deployment or StatefulSet (default Evict)
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+
+	// MaxConcurrentRestarts is how many pods of the workload may be restarting or
+	// unavailable at once before further restarts are skipped (default 1)
+	// +optional
+	MaxConcurrentRestarts *int32 `json:"maxConcurrentRestarts,omitempty"`
+}
+
+// RollbackParameters defines how RollbackDeployment picks the revision to restore
+type RollbackParameters struct {
+	// MinHealthyDuration restricts rollbacks to revisions that were observed fully
+	// available for at least this long (e.g. "10m"). When empty any revision
+	// that was observed fully available can be restored.
+	// +optional
+	MinHealthyDuration string `json:"minHealthyDuration,omitempty"`
+}
+
+// Verification results
+const (
+	VerificationPending      = "Pending"
+	VerificationEffective    = "Effective"
+	VerificationIneffective  = "Ineffective"
+	VerificationInconclusive = "Inconclusive"
+)
+
+// VerificationParameters defines how the effect of an action is checked after it is applied
+type VerificationParameters struct {
+	// Window within which the remediation must take effect (e.g. "5m")
+	Window string `json:"window"`
+
+	// MinImprovement is how much every condition of the rule must drop below its value
+	// when the action was taken, as a percentage (e.g. "20%"), for the remediation to count
+	// as effective. The remediation is always effective once the conditions clear.
+	// +optional
+	MinImprovement string `json:"minImprovement,omitempty"`
+
+	// RevertIfIneffective restores the target from its backup when the remediation was
+	// ineffective (default true)
+	// +optional
+	RevertIfIneffective *bool `json:"revertIfIneffective,omitempty"`
+}
+
+// ResourceAdjustment describes how one resource of the selected containers is raised.
+// Absolute values take precedence over the multiplier; values are never lowered.
+type ResourceAdjustment struct {
+	// Resource to adjust, cpu or memory
+	Resource string `json:"resource"`
+
+	// Request is the new absolute request (e.g. "500m", "512Mi")
+	// +optional
+	Request string `json:"request,omitempty"`
+
+	// Limit is the new absolute limit (e.g. "1", "1Gi")
+	// +optional
+	Limit string `json:"limit,omitempty"`
+
+	// Multiplier scales the current request and limit (e.g. "1.5")
+	// +optional
+	Multiplier string `json:"multiplier,omitempty"`
+
+	// Max caps the resulting request and limit (e.g. "2Gi")
+	// +optional
+	Max string `json:"max,omitempty"`
+}
+
+// ResourceParameters defines how UpdateResources changes container resources
+type ResourceParameters struct {
+	// Containers to update; all containers when empty
+	// +optional
+	Containers []string `json:"containers,omitempty"`
+
+	// Adjustments to apply to each selected container
+	Adjustments []ResourceAdjustment `json:"adjustments"`
+
+	// Duration to keep the new resources before reverting them (e.g. "1h").
+	// The change is kept when empty.
+	// +optional
+	Duration string `json:"duration,omitempty"`
+}
+
 // Action defines what remediation to take
 type Action struct {
 	// Type of action to take
@@ -100,6 +284,26 @@ type Action struct {
 	// +optional
 	ScalingParams *ScalingParameters `json:"scalingParams,omitempty"`
 
+	// RestartParams configures RestartPod actions. When the action has no target,
+	// the target pod furthest over the rule's thresholds is restarted.
+	// +optional
+	RestartParams *RestartParameters `json:"restartParams,omitempty"`
+
+	// RollbackParams configures RollbackDeployment actions. When the action has no
+	// target, the Deployment owning the target pod furthest over the rule's thresholds
+	// is rolled back.
+	// +optional
+	RollbackParams *RollbackParameters `json:"rollbackParams,omitempty"`
+
+	// ResourceParams configures UpdateResources actions. When the action has no target,
+	// the workload owning the target pod furthest over the rule's thresholds is updated.
+	// +optional
+	ResourceParams *ResourceParameters `json:"resourceParams,omitempty"`
+
+	// Verification checks that the action helped within a window and reverts it if not
+	// +optional
+	Verification *VerificationParameters `json:"verification,omitempty"`
+
 	// PreActionHook webhook to call before taking action
 	// +optional
 	PreActionHook string `json:"preActionHook,omitempty"`
@@ -118,7 +322,7 @@ type Rule struct {
 	// Name of the rule
 	Name string `json:"name"`
 
-	// Conditions that trigger the rule
+	// Conditions that trigger the rule; all of them must be met
 	Conditions []Condition `json:"conditions"`
 
 	// Actions to take when conditions are met
@@ -140,8 +344,10 @@ type SelfRemediationPolicySpec struct {
 	// TargetRef specifies the target resource to monitor
 	TargetRef TargetReference `json:"targetRef"`
 
-	// CPUThreshold is the CPU usage threshold in cores
-	CPUThreshold string `json:"cpuThreshold"`
+	// CPUThreshold is the CPU usage threshold in cores, used as a CPUUsage
+	// condition for rules that declare no conditions of their own
+	// +optional
+	CPUThreshold string `json:"cpuThreshold,omitempty"`
 
 	// Rules defines the remediation rules
 	Rules []Rule `json:"rules"`
@@ -157,14 +363,166 @@ type SelfRemediationPolicySpec struct {
 
 // TargetReference contains the reference to the target resource
 type TargetReference struct {
+	// Name of the target resource, omitted when Selector is set
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the target resource
+	Namespace string `json:"namespace"`
+
+	// Kind of the target resource: Pod, Deployment, StatefulSet or DaemonSet. Policies
+	// targeting a workload monitor all of its pods.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Selector selects the pods to monitor by label, instead of naming a resource
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// PendingCondition records a condition that is currently over its threshold
+type PendingCondition struct {
+	// Rule the condition belongs to
+	Rule string `json:"rule"`
+
+	// Type of the condition
+	Type ConditionType `json:"type"`
+
+	// Threshold of the condition
+	Threshold string `json:"threshold"`
+
+	// Key identifies the condition within its rule by a hash of its settings, so that
+	// conditions of the same type and threshold are told apart
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// PendingSince is when the condition was first observed over its threshold
+	PendingSince metav1.Time `json:"pendingSince"`
+
+	// Sustained indicates the condition has been over its threshold for its full duration
+	// +optional
+	Sustained bool `json:"sustained,omitempty"`
+}
+
+// TargetCooldown records when a remediated target may be acted on again
+type TargetCooldown struct {
+	// Kind of the target resource
+	Kind string `json:"kind"`
+
 	// Name of the target resource
 	Name string `json:"name"`
 
 	// Namespace of the target resource
 	Namespace string `json:"namespace"`
 
-	// Kind of the target resource
+	// LastActionTime is when the target was last remediated
+	LastActionTime metav1.Time `json:"lastActionTime"`
+
+	// NextEligibleTime is the earliest time the target may be remediated again
+	NextEligibleTime metav1.Time `json:"nextEligibleTime"`
+}
+
+// PendingReversion records a temporary change that is scheduled to be reverted
+type PendingReversion struct {
+	// Kind of the changed resource
 	Kind string `json:"kind"`
+
+	// Name of the changed resource
+	Name string `json:"name"`
+
+	// Namespace of the changed resource
+	Namespace string `json:"namespace"`
+
+	// RevertAt is when the change is due to be reverted
+	RevertAt metav1.Time `json:"revertAt"`
+}
+
+// ConditionSample is a measured value of one condition
+type ConditionSample struct {
+	// Type of the condition
+	Type ConditionType `json:"type"`
+
+	// Value in the unit of the condition's threshold
+	Value string `json:"value"`
+}
+
+// RemediationVerification records the check of whether an action helped
+type RemediationVerification struct {
+	// Rule that triggered the action
+	Rule string `json:"rule"`
+
+	// Action that was verified
+	Action ActionType `json:"action"`
+
+	// Kind of the changed resource
+	Kind string `json:"kind"`
+
+	// Name of the changed resource
+	Name string `json:"name"`
+
+	// Namespace of the changed resource
+	Namespace string `json:"namespace"`
+
+	// Backup taken before the action, used to revert it
+	// +optional
+	Backup string `json:"backup,omitempty"`
+
+	// StartTime is when the action was taken
+	StartTime metav1.Time `json:"startTime"`
+
+	// Deadline is when the window closes
+	Deadline metav1.Time `json:"deadline"`
+
+	// Baseline holds the condition values when the action was taken
+	// +optional
+	Baseline []ConditionSample `json:"baseline,omitempty"`
+
+	// Observed holds the latest condition values
+	// +optional
+	Observed []ConditionSample `json:"observed,omitempty"`
+
+	// Improvement is the smallest drop of any condition from its baseline, as a percentage
+	// +optional
+	Improvement string `json:"improvement,omitempty"`
+
+	// Result of the verification: Pending, Effective, Ineffective or Inconclusive
+	Result string `json:"result"`
+
+	// Reverted is set when an ineffective change was restored from its backup
+	// +optional
+	Reverted bool `json:"reverted,omitempty"`
+
+	// Message describing the result
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// CompletionTime is when the result was decided
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// RemediationFailure records a rule execution in which an action failed
+type RemediationFailure struct {
+	// Rule whose actions were executed
+	Rule string `json:"rule"`
+
+	// Action that failed
+	Action ActionType `json:"action"`
+
+	// Message describing the failure
+	Message string `json:"message"`
+
+	// Time of the failure
+	Time metav1.Time `json:"time"`
+
+	// RolledBack lists the resources changed by earlier actions of the rule that were
+	// restored from their backups
+	// +optional
+	RolledBack []string `json:"rolledBack,omitempty"`
+
+	// RollbackErrors lists the earlier changes that could not be restored
+	// +optional
+	RollbackErrors []string `json:"rollbackErrors,omitempty"`
 }
 
 // SelfRemediationPolicyStatus defines the observed state
@@ -183,6 +541,42 @@ type SelfRemediationPolicyStatus struct {
 
 	// Active indicates if the policy is currently active
 	Active bool `json:"active"`
+
+	// PendingConditions lists the conditions currently over their thresholds
+	// +optional
+	PendingConditions []PendingCondition `json:"pendingConditions,omitempty"`
+
+	// LastRemediationTime is when the policy last took a remediation action
+	// +optional
+	LastRemediationTime *metav1.Time `json:"lastRemediationTime,omitempty"`
+
+	// NextEligibleTime is the earliest time the policy may take another action
+	// +optional
+	NextEligibleTime *metav1.Time `json:"nextEligibleTime,omitempty"`
+
+	// TargetCooldowns lists the targets that are still in their cooldown period
+	// +optional
+	TargetCooldowns []TargetCooldown `json:"targetCooldowns,omitempty"`
+
+	// PendingReversions lists the temporary changes still waiting to be reverted
+	// +optional
+	PendingReversions []PendingReversion `json:"pendingReversions,omitempty"`
+
+	// LastFailure records the most recent rule execution in which an action failed
+	// +optional
+	LastFailure *RemediationFailure `json:"lastFailure,omitempty"`
+
+	// Verifications lists the verifications in progress and the most recent results
+	// +optional
+	Verifications []RemediationVerification `json:"verifications,omitempty"`
+
+	// EffectiveRemediations counts the verified actions that helped
+	// +optional
+	EffectiveRemediations int32 `json:"effectiveRemediations,omitempty"`
+
+	// IneffectiveRemediations counts the verified actions that did not help
+	// +optional
+	IneffectiveRemediations int32 `json:"ineffectiveRemediations,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -34,6 +34,26 @@ func (in *Action) DeepCopyInto(out *Action) {
 		*out = new(ScalingParameters)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RestartParams != nil {
+		in, out := &in.RestartParams, &out.RestartParams
+		*out = new(RestartParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RollbackParams != nil {
+		in, out := &in.RollbackParams, &out.RollbackParams
+		*out = new(RollbackParameters)
+		**out = **in
+	}
+	if in.ResourceParams != nil {
+		in, out := &in.ResourceParams, &out.ResourceParams
+		*out = new(ResourceParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(VerificationParameters)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Action.
@@ -49,6 +69,16 @@ func (in *Action) DeepCopy() *Action {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
+	if in.MinCount != nil {
+		in, out := &in.MinCount, &out.MinCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MetricSelector != nil {
+		in, out := &in.MetricSelector, &out.MetricSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
@@ -61,6 +91,21 @@ func (in *Condition) DeepCopy() *Condition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConditionSample) DeepCopyInto(out *ConditionSample) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionSample.
+func (in *ConditionSample) DeepCopy() *ConditionSample {
+	if in == nil {
+		return nil
+	}
+	out := new(ConditionSample)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaIntegration) DeepCopyInto(out *GrafanaIntegration) {
 	*out = *in
@@ -76,6 +121,175 @@ func (in *GrafanaIntegration) DeepCopy() *GrafanaIntegration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeMedicConfig) DeepCopyInto(out *KubeMedicConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeMedicConfig.
+func (in *KubeMedicConfig) DeepCopy() *KubeMedicConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeMedicConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubeMedicConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeMedicConfigList) DeepCopyInto(out *KubeMedicConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubeMedicConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeMedicConfigList.
+func (in *KubeMedicConfigList) DeepCopy() *KubeMedicConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeMedicConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubeMedicConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeMedicConfigSpec) DeepCopyInto(out *KubeMedicConfigSpec) {
+	*out = *in
+	in.SafetyLimits.DeepCopyInto(&out.SafetyLimits)
+	if in.DeniedNamespaces != nil {
+		in, out := &in.DeniedNamespaces, &out.DeniedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedSelectors != nil {
+		in, out := &in.ProtectedSelectors, &out.ProtectedSelectors
+		*out = make([]v1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NamespaceOverrides != nil {
+		in, out := &in.NamespaceOverrides, &out.NamespaceOverrides
+		*out = make([]NamespaceOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.PolicyDefaults = in.PolicyDefaults
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeMedicConfigSpec.
+func (in *KubeMedicConfigSpec) DeepCopy() *KubeMedicConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeMedicConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceOverride) DeepCopyInto(out *NamespaceOverride) {
+	*out = *in
+	in.SafetyLimits.DeepCopyInto(&out.SafetyLimits)
+	if in.ProtectedSelectors != nil {
+		in, out := &in.ProtectedSelectors, &out.ProtectedSelectors
+		*out = make([]v1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceOverride.
+func (in *NamespaceOverride) DeepCopy() *NamespaceOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingCondition) DeepCopyInto(out *PendingCondition) {
+	*out = *in
+	in.PendingSince.DeepCopyInto(&out.PendingSince)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingCondition.
+func (in *PendingCondition) DeepCopy() *PendingCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingReversion) DeepCopyInto(out *PendingReversion) {
+	*out = *in
+	in.RevertAt.DeepCopyInto(&out.RevertAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingReversion.
+func (in *PendingReversion) DeepCopy() *PendingReversion {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingReversion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyDefaults) DeepCopyInto(out *PolicyDefaults) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyDefaults.
+func (in *PolicyDefaults) DeepCopy() *PolicyDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemediationBackup) DeepCopyInto(out *RemediationBackup) {
 	*out = *in
@@ -161,6 +375,11 @@ func (in *RemediationBackupSpec) DeepCopyInto(out *RemediationBackupSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.ModifiedPaths != nil {
+		in, out := &in.ModifiedPaths, &out.ModifiedPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationBackupSpec.
@@ -197,6 +416,201 @@ func (in *RemediationBackupStatus) DeepCopy() *RemediationBackupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationFailure) DeepCopyInto(out *RemediationFailure) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.RolledBack != nil {
+		in, out := &in.RolledBack, &out.RolledBack
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RollbackErrors != nil {
+		in, out := &in.RollbackErrors, &out.RollbackErrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationFailure.
+func (in *RemediationFailure) DeepCopy() *RemediationFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationRestore) DeepCopyInto(out *RemediationRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationRestore.
+func (in *RemediationRestore) DeepCopy() *RemediationRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RemediationRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationRestoreList) DeepCopyInto(out *RemediationRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RemediationRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationRestoreList.
+func (in *RemediationRestoreList) DeepCopy() *RemediationRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RemediationRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationRestoreSpec) DeepCopyInto(out *RemediationRestoreSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationRestoreSpec.
+func (in *RemediationRestoreSpec) DeepCopy() *RemediationRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationRestoreStatus) DeepCopyInto(out *RemediationRestoreStatus) {
+	*out = *in
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationRestoreStatus.
+func (in *RemediationRestoreStatus) DeepCopy() *RemediationRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationVerification) DeepCopyInto(out *RemediationVerification) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.Deadline.DeepCopyInto(&out.Deadline)
+	if in.Baseline != nil {
+		in, out := &in.Baseline, &out.Baseline
+		*out = make([]ConditionSample, len(*in))
+		copy(*out, *in)
+	}
+	if in.Observed != nil {
+		in, out := &in.Observed, &out.Observed
+		*out = make([]ConditionSample, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationVerification.
+func (in *RemediationVerification) DeepCopy() *RemediationVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceAdjustment) DeepCopyInto(out *ResourceAdjustment) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceAdjustment.
+func (in *ResourceAdjustment) DeepCopy() *ResourceAdjustment {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceAdjustment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceParameters) DeepCopyInto(out *ResourceParameters) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Adjustments != nil {
+		in, out := &in.Adjustments, &out.Adjustments
+		*out = make([]ResourceAdjustment, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceParameters.
+func (in *ResourceParameters) DeepCopy() *ResourceParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceReference) DeepCopyInto(out *ResourceReference) {
 	*out = *in
@@ -212,13 +626,50 @@ func (in *ResourceReference) DeepCopy() *ResourceReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestartParameters) DeepCopyInto(out *RestartParameters) {
+	*out = *in
+	if in.MaxConcurrentRestarts != nil {
+		in, out := &in.MaxConcurrentRestarts, &out.MaxConcurrentRestarts
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestartParameters.
+func (in *RestartParameters) DeepCopy() *RestartParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(RestartParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackParameters) DeepCopyInto(out *RollbackParameters) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackParameters.
+func (in *RollbackParameters) DeepCopy() *RollbackParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Rule) DeepCopyInto(out *Rule) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]Condition, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Actions != nil {
 		in, out := &in.Actions, &out.Actions
@@ -239,6 +690,51 @@ func (in *Rule) DeepCopy() *Rule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SafetyLimits) DeepCopyInto(out *SafetyLimits) {
+	*out = *in
+	if in.MaxScaleFactor != nil {
+		in, out := &in.MaxScaleFactor, &out.MaxScaleFactor
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinPods != nil {
+		in, out := &in.MinPods, &out.MinPods
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxActionsPerHour != nil {
+		in, out := &in.MaxActionsPerHour, &out.MaxActionsPerHour
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxActionsPerHourPerNamespace != nil {
+		in, out := &in.MaxActionsPerHourPerNamespace, &out.MaxActionsPerHourPerNamespace
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxActionsPerHourPerPolicy != nil {
+		in, out := &in.MaxActionsPerHourPerPolicy, &out.MaxActionsPerHourPerPolicy
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxActionsPerHourPerTarget != nil {
+		in, out := &in.MaxActionsPerHourPerTarget, &out.MaxActionsPerHourPerTarget
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SafetyLimits.
+func (in *SafetyLimits) DeepCopy() *SafetyLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(SafetyLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScalingParameters) DeepCopyInto(out *ScalingParameters) {
 	*out = *in
@@ -247,6 +743,11 @@ func (in *ScalingParameters) DeepCopyInto(out *ScalingParameters) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.RevertStepSize != nil {
+		in, out := &in.RevertStepSize, &out.RevertStepSize
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingParameters.
@@ -321,6 +822,7 @@ func (in *SelfRemediationPolicyList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SelfRemediationPolicySpec) DeepCopyInto(out *SelfRemediationPolicySpec) {
 	*out = *in
+	in.TargetRef.DeepCopyInto(&out.TargetRef)
 	if in.Rules != nil {
 		in, out := &in.Rules, &out.Rules
 		*out = make([]Rule, len(*in))
@@ -352,6 +854,48 @@ func (in *SelfRemediationPolicyStatus) DeepCopyInto(out *SelfRemediationPolicySt
 		in, out := &in.LastEvaluationTime, &out.LastEvaluationTime
 		*out = (*in).DeepCopy()
 	}
+	in.LastChecked.DeepCopyInto(&out.LastChecked)
+	if in.PendingConditions != nil {
+		in, out := &in.PendingConditions, &out.PendingConditions
+		*out = make([]PendingCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastRemediationTime != nil {
+		in, out := &in.LastRemediationTime, &out.LastRemediationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextEligibleTime != nil {
+		in, out := &in.NextEligibleTime, &out.NextEligibleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.TargetCooldowns != nil {
+		in, out := &in.TargetCooldowns, &out.TargetCooldowns
+		*out = make([]TargetCooldown, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PendingReversions != nil {
+		in, out := &in.PendingReversions, &out.PendingReversions
+		*out = make([]PendingReversion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		*out = new(RemediationFailure)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verifications != nil {
+		in, out := &in.Verifications, &out.Verifications
+		*out = make([]RemediationVerification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfRemediationPolicyStatus.
@@ -378,3 +922,60 @@ func (in *Target) DeepCopy() *Target {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetCooldown) DeepCopyInto(out *TargetCooldown) {
+	*out = *in
+	in.LastActionTime.DeepCopyInto(&out.LastActionTime)
+	in.NextEligibleTime.DeepCopyInto(&out.NextEligibleTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetCooldown.
+func (in *TargetCooldown) DeepCopy() *TargetCooldown {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetCooldown)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetReference) DeepCopyInto(out *TargetReference) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetReference.
+func (in *TargetReference) DeepCopy() *TargetReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationParameters) DeepCopyInto(out *VerificationParameters) {
+	*out = *in
+	if in.RevertIfIneffective != nil {
+		in, out := &in.RevertIfIneffective, &out.RevertIfIneffective
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationParameters.
+func (in *VerificationParameters) DeepCopy() *VerificationParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationParameters)
+	in.DeepCopyInto(out)
+	return out
+}
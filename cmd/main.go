@@ -17,10 +17,12 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -28,11 +30,15 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/metrics/pkg/client/clientset/versioned"
+	custommetrics "k8s.io/metrics/pkg/client/custom_metrics"
+	externalmetrics "k8s.io/metrics/pkg/client/external_metrics"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -40,6 +46,7 @@ import (
 	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
 	"github.com/ikepcampbell/kubemedic/internal/controller"
 	"github.com/ikepcampbell/kubemedic/internal/version"
+	"github.com/ikepcampbell/kubemedic/pkg/metrics"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -62,6 +69,8 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var printVersion bool
+	var stateNamespace string
+	var prometheusURL string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -74,6 +83,11 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.BoolVar(&printVersion, "version", false, "Print version information and exit")
+	flag.StringVar(&stateNamespace, "state-namespace", "kubemedic",
+		"The namespace of the ConfigMap holding the action rate limiter state.")
+	flag.StringVar(&prometheusURL, "prometheus-url", "",
+		"The address of the Prometheus HTTP API for Prometheus conditions, e.g. http://prometheus.monitoring:9090. "+
+			"Prometheus conditions fail when it is not set.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -163,15 +177,66 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = controller.NewSelfRemediationPolicyReconciler(
+	policyReconciler := controller.NewSelfRemediationPolicyReconciler(
 		mgr.GetClient(),
 		mgr.GetScheme(),
 		metricsClient,
 		mgr.GetEventRecorderFor("kubemedic"),
-	).SetupWithManager(mgr); err != nil {
+	)
+	policyReconciler.RateLimiter = controller.NewActionRateLimiter(mgr.GetClient(), mgr.GetAPIReader(), stateNamespace)
+
+	// Add the metrics sources beyond metrics-server
+	if prometheusURL != "" {
+		prometheus, err := metrics.NewPrometheus(prometheusURL, nil)
+		if err != nil {
+			setupLog.Error(err, "unable to create Prometheus metrics source")
+			os.Exit(1)
+		}
+		policyReconciler.Evaluator.AddSource(prometheus)
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	availableAPIs := custommetrics.NewAvailableAPIsGetter(discoveryClient)
+	// Pick up custom metrics API versions installed after startup
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		custommetrics.PeriodicallyInvalidate(availableAPIs, 5*time.Minute, ctx.Done())
+		return nil
+	})); err != nil {
+		setupLog.Error(err, "unable to watch custom metrics API versions")
+		os.Exit(1)
+	}
+	policyReconciler.Evaluator.AddSource(metrics.NewCustomMetrics(
+		custommetrics.NewForConfig(mgr.GetConfig(), mgr.GetRESTMapper(), availableAPIs)))
+	externalMetricsClient, err := externalmetrics.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create external metrics client")
+		os.Exit(1)
+	}
+	policyReconciler.Evaluator.AddSource(metrics.NewExternalMetrics(externalMetricsClient))
+
+	if err = policyReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SelfRemediationPolicy")
 		os.Exit(1)
 	}
+	if err = controller.NewRemediationBackupReconciler(
+		mgr.GetClient(),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("kubemedic"),
+	).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RemediationBackup")
+		os.Exit(1)
+	}
+	if err = controller.NewRemediationRestoreReconciler(
+		mgr.GetClient(),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("kubemedic"),
+	).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RemediationRestore")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -9,6 +9,9 @@ import (
 	"path/filepath"
 	"syscall"
 
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -16,10 +19,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
 	"github.com/ikepcampbell/kubemedic/internal/version"
 	webhookpkg "github.com/ikepcampbell/kubemedic/pkg/webhook"
 )
 
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(remediationv1alpha1.AddToScheme(scheme))
+}
+
 func main() {
 	var tlsCertFile string
 	var tlsKeyFile string
@@ -51,17 +62,9 @@ func main() {
 		os.Exit(1)
 	}
 
-	certDir := filepath.Dir(tlsCertFile)
-	certName := filepath.Base(tlsCertFile)
-	keyDir := filepath.Dir(tlsKeyFile)
-	keyName := filepath.Base(tlsKeyFile)
-	if certDir != keyDir {
-		klog.Error(fmt.Errorf("tls cert and key must be in the same directory"), "invalid TLS flags", "tls-cert-file", tlsCertFile, "tls-private-key-file", tlsKeyFile)
-		os.Exit(1)
-	}
-
 	// Create a new manager to provide shared dependencies and start components
 	mgr, err := manager.New(cfg, manager.Options{
+		Scheme: scheme,
 		WebhookServer: webhook.NewServer(webhook.Options{
 			Port:     8443,
 			CertDir:  certDir,
@@ -78,14 +81,18 @@ func main() {
 	}
 
 	// Create and initialize the validator
-	validator := &webhookpkg.KubeMedicValidator{
-		Client: mgr.GetClient(),
-	}
+	validator := webhookpkg.NewKubeMedicValidator(mgr.GetClient(), mgr.GetScheme())
+
+	// Create the defaulter, which runs before the validator
+	defaulter := webhookpkg.NewKubeMedicDefaulter(mgr.GetClient(), mgr.GetScheme())
 
-	// Register the webhook with the manager
+	// Register the webhooks with the manager
 	mgr.GetWebhookServer().Register("/validate", &admission.Webhook{
 		Handler: validator,
 	})
+	mgr.GetWebhookServer().Register("/mutate", &admission.Webhook{
+		Handler: defaulter,
+	})
 
 	// Create a context that is canceled when a termination signal is received
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
@@ -0,0 +1,238 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+const (
+	// backupPolicyLabel marks a RemediationBackup with the name of the policy that created it
+	backupPolicyLabel = "kubemedic.io/policy"
+	// backupAnnotation names the RemediationBackup, in the owning policy's namespace, that
+	// holds the state a pending reversion restores
+	backupAnnotation = "kubemedic.io/backup"
+
+	// defaultBackupTTL is how long backups are kept after any scheduled reversion
+	defaultBackupTTL = 24 * time.Hour
+)
+
+// backupContentHash returns the integrity hash recorded for a backup's original state
+func backupContentHash(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// verifyBackupContent checks the backup's original state against its recorded hash
+func verifyBackupContent(backup *remediationv1alpha1.RemediationBackup) error {
+	if len(backup.Spec.OriginalState.Raw) == 0 {
+		return fmt.Errorf("backup has no original state")
+	}
+	if backup.Status.ContentHash == "" {
+		return fmt.Errorf("backup has no content hash")
+	}
+	if hash := backupContentHash(backup.Spec.OriginalState.Raw); hash != backup.Status.ContentHash {
+		return fmt.Errorf("content hash mismatch: recorded %s, computed %s", backup.Status.ContentHash, hash)
+	}
+	return nil
+}
+
+// snapshot backs up obj before an action changes the fields at paths. When the change will
+// be reverted after revertAfter, obj is pointed at the backup so the reversion restores
+// those fields from it; an earlier backup of a change that is still pending reversion is
+// kept, as it holds the true original.
+func (r *SelfRemediationPolicyReconciler) snapshot(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	actionType remediationv1alpha1.ActionType,
+	obj client.Object,
+	revertAfter time.Duration,
+	paths ...string,
+) (*remediationv1alpha1.RemediationBackup, error) {
+	backup, err := r.createBackup(ctx, policy, actionType, obj, revertAfter+defaultBackupTTL, paths)
+	if err != nil {
+		return nil, err
+	}
+	if revertAfter <= 0 {
+		return backup, nil
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if _, ok := annotations[backupAnnotation]; !ok || !hasPendingReversion(obj) {
+		annotations[backupAnnotation] = backup.Name
+	}
+	obj.SetAnnotations(annotations)
+	addRevertPaths(obj, paths...)
+	return backup, nil
+}
+
+// createBackup records the state of obj in a RemediationBackup before a remediation
+// action changes it. The backup lives in the policy's namespace and is owned by the policy;
+// its content hash and size are recorded in the backup status. A backup whose hash cannot
+// be recorded is deleted again.
+func (r *SelfRemediationPolicyReconciler) createBackup(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	actionType remediationv1alpha1.ActionType,
+	obj client.Object,
+	ttl time.Duration,
+	modifiedPaths []string,
+) (*remediationv1alpha1.RemediationBackup, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine kind of %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+
+	// Typed objects read through the client carry no TypeMeta; record it so the
+	// backup is self-describing
+	original := obj.DeepCopyObject().(client.Object)
+	original.GetObjectKind().SetGroupVersionKind(gvk)
+	raw, err := json.Marshal(original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize %s %s: %w", gvk.Kind, client.ObjectKeyFromObject(obj), err)
+	}
+
+	backup := &remediationv1alpha1.RemediationBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-%s-", policy.Name, strings.ToLower(gvk.Kind)),
+			Namespace:    policy.Namespace,
+			Labels: map[string]string{
+				backupPolicyLabel: policy.Name,
+			},
+		},
+		Spec: remediationv1alpha1.RemediationBackupSpec{
+			OriginalState: runtime.RawExtension{Raw: raw},
+			ResourceRef: remediationv1alpha1.ResourceReference{
+				APIGroup:  gvk.Group,
+				Kind:      gvk.Kind,
+				Name:      obj.GetName(),
+				Namespace: obj.GetNamespace(),
+			},
+			PolicyRef: remediationv1alpha1.ResourceReference{
+				APIGroup:  remediationv1alpha1.GroupVersion.Group,
+				Kind:      "SelfRemediationPolicy",
+				Name:      policy.Name,
+				Namespace: policy.Namespace,
+			},
+			ActionType:          string(actionType),
+			BackupTime:          metav1.NewTime(time.Now()),
+			TTL:                 &metav1.Duration{Duration: ttl},
+			OriginalLabels:      obj.GetLabels(),
+			OriginalAnnotations: obj.GetAnnotations(),
+			ModifiedPaths:       modifiedPaths,
+		},
+	}
+	if err := controllerutil.SetOwnerReference(policy, backup, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set backup owner: %w", err)
+	}
+
+	if err := r.Create(ctx, backup); err != nil {
+		return nil, fmt.Errorf("failed to create backup of %s %s: %w", gvk.Kind, client.ObjectKeyFromObject(obj), err)
+	}
+
+	backup.Status.ContentHash = backupContentHash(raw)
+	backup.Status.BackupSizeBytes = int64(len(raw))
+	if err := r.Status().Update(ctx, backup); err != nil {
+		// A backup without a hash can never be verified or restored
+		if delErr := r.Delete(ctx, backup); delErr != nil && !errors.IsNotFound(delErr) {
+			log.FromContext(ctx).Error(delErr, "Failed to delete backup without a hash",
+				"backup", client.ObjectKeyFromObject(backup).String())
+		}
+		return nil, fmt.Errorf("failed to record hash of backup %s/%s: %w", backup.Namespace, backup.Name, err)
+	}
+	recordBackup(ctx, backup)
+	return backup, nil
+}
+
+// originalState returns the object as it was before the pending change, decoded from the
+// backup the object points at. It returns nil when the object has no backup or the backup
+// is missing, fails its integrity check or belongs to a different object.
+func (r *SelfRemediationPolicyReconciler) originalState(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	obj client.Object,
+) (client.Object, error) {
+	name, ok := obj.GetAnnotations()[backupAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	log := log.FromContext(ctx).WithValues("backup", name, "target", client.ObjectKeyFromObject(obj).String())
+
+	var backup remediationv1alpha1.RemediationBackup
+	if err := r.Get(ctx, types.NamespacedName{Namespace: policy.Namespace, Name: name}, &backup); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Backup for pending reversion not found")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get backup %s/%s: %w", policy.Namespace, name, err)
+	}
+	if err := verifyBackupContent(&backup); err != nil {
+		log.Info("Ignoring backup that failed verification", "reason", err.Error())
+		r.Recorder.Eventf(policy, corev1.EventTypeWarning, "BackupInvalid",
+			"Backup %s of %s %s/%s cannot be used: %v",
+			name, targetKind(obj), obj.GetNamespace(), obj.GetName(), err)
+		return nil, nil
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine kind of %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+	ref := backup.Spec.ResourceRef
+	if ref.Kind != gvk.Kind || ref.Namespace != obj.GetNamespace() || ref.Name != obj.GetName() {
+		log.Info("Ignoring backup of a different resource", "resource", ref)
+		return nil, nil
+	}
+
+	decoded, err := r.Scheme.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", gvk.Kind, err)
+	}
+	original, ok := decoded.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a Kubernetes object", gvk.Kind)
+	}
+	if err := json.Unmarshal(backup.Spec.OriginalState.Raw, original); err != nil {
+		return nil, fmt.Errorf("failed to decode backup %s/%s: %w", policy.Namespace, name, err)
+	}
+	if original.GetUID() != obj.GetUID() {
+		log.Info("Ignoring backup of a recreated resource")
+		return nil, nil
+	}
+	return original, nil
+}
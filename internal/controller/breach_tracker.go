@@ -0,0 +1,226 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// conditionBreach tracks when a single condition started breaching its threshold
+type conditionBreach struct {
+	Rule      string
+	Condition remediationv1alpha1.Condition
+	Since     time.Time
+	Sustained bool
+}
+
+// BreachTracker keeps the breach history of every policy condition so a rule only
+// fires once its conditions have been continuously met for their declared Duration.
+// The history is mirrored into the policy status and restored from it after a restart.
+type BreachTracker struct {
+	// policies maps a policy to the breaches of its conditions, keyed by breachKey
+	policies sync.Map
+}
+
+// breachKey identifies a condition of a rule by the hash of its settings, so that
+// conditions that differ only in their query, metric, container or aggregation are
+// tracked apart
+func breachKey(rule string, condition remediationv1alpha1.Condition) string {
+	return rule + "/" + conditionKey(condition)
+}
+
+// conditionKey hashes the settings of a condition. The duration is left out: changing how
+// long a condition must be met does not restart its breach.
+func conditionKey(condition remediationv1alpha1.Condition) string {
+	condition.Duration = ""
+	encoded, err := json.Marshal(condition)
+	if err != nil {
+		// A condition always encodes; fall back to the fields that identify it best
+		encoded = []byte(string(condition.Type) + "/" + condition.Threshold)
+	}
+	hash := fnv.New64a()
+	hash.Write(encoded)
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
+
+// Restore seeds the tracker for a policy from its persisted status. It is a no-op
+// when the tracker already holds state for the policy. Pending conditions that the
+// policy no longer declares are dropped.
+func (t *BreachTracker) Restore(policy *remediationv1alpha1.SelfRemediationPolicy) {
+	name := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}
+	if _, ok := t.policies.Load(name); ok {
+		return
+	}
+
+	declared := make(map[string]remediationv1alpha1.Condition)
+	for _, rule := range policy.Spec.Rules {
+		for _, condition := range effectiveRule(policy, rule).Conditions {
+			declared[breachKey(rule.Name, condition)] = condition
+		}
+	}
+
+	breaches := make(map[string]*conditionBreach, len(policy.Status.PendingConditions))
+	for _, p := range policy.Status.PendingConditions {
+		key := p.Rule + "/" + p.Key
+		condition, ok := declared[key]
+		if !ok {
+			continue
+		}
+		breaches[key] = &conditionBreach{
+			Rule:      p.Rule,
+			Condition: condition,
+			Since:     p.PendingSince.Time,
+			Sustained: p.Sustained,
+		}
+	}
+	t.policies.LoadOrStore(name, breaches)
+}
+
+// Observe records the latest result of a condition and reports whether it has been
+// met continuously for its duration. The second return value is how long remains
+// until the condition becomes sustained, or zero when it is sustained or not met.
+func (t *BreachTracker) Observe(
+	policy types.NamespacedName,
+	rule string,
+	condition remediationv1alpha1.Condition,
+	met bool,
+	now time.Time,
+) (bool, time.Duration, error) {
+	duration, err := conditionDuration(condition)
+	if err != nil {
+		return false, 0, err
+	}
+
+	value, _ := t.policies.LoadOrStore(policy, map[string]*conditionBreach{})
+	breaches := value.(map[string]*conditionBreach)
+	key := breachKey(rule, condition)
+
+	if !met {
+		delete(breaches, key)
+		return false, 0, nil
+	}
+
+	breach, ok := breaches[key]
+	if !ok {
+		breach = &conditionBreach{Rule: rule, Condition: condition, Since: now}
+		breaches[key] = breach
+	}
+
+	elapsed := now.Sub(breach.Since)
+	breach.Sustained = elapsed >= duration
+	if breach.Sustained {
+		return true, 0, nil
+	}
+	return false, duration - elapsed, nil
+}
+
+// RuleBreaching reports whether any condition of the rule is currently over its threshold
+func (t *BreachTracker) RuleBreaching(policy types.NamespacedName, rule string) bool {
+	value, ok := t.policies.Load(policy)
+	if !ok {
+		return false
+	}
+	for _, breach := range value.(map[string]*conditionBreach) {
+		if breach.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Prune drops breaches for conditions that are no longer declared by the policy
+func (t *BreachTracker) Prune(policy *remediationv1alpha1.SelfRemediationPolicy) {
+	value, ok := t.policies.Load(types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name})
+	if !ok {
+		return
+	}
+	breaches := value.(map[string]*conditionBreach)
+
+	declared := make(map[string]bool)
+	for _, rule := range policy.Spec.Rules {
+		for _, condition := range effectiveRule(policy, rule).Conditions {
+			declared[breachKey(rule.Name, condition)] = true
+		}
+	}
+	for key := range breaches {
+		if !declared[key] {
+			delete(breaches, key)
+		}
+	}
+}
+
+// Forget removes all tracking for a policy
+func (t *BreachTracker) Forget(policy types.NamespacedName) {
+	t.policies.Delete(policy)
+}
+
+// PendingConditions returns the policy's breaches in their status representation
+func (t *BreachTracker) PendingConditions(policy types.NamespacedName) []remediationv1alpha1.PendingCondition {
+	value, ok := t.policies.Load(policy)
+	if !ok {
+		return nil
+	}
+	breaches := value.(map[string]*conditionBreach)
+
+	pending := make([]remediationv1alpha1.PendingCondition, 0, len(breaches))
+	for _, breach := range breaches {
+		pending = append(pending, remediationv1alpha1.PendingCondition{
+			Rule:         breach.Rule,
+			Type:         breach.Condition.Type,
+			Threshold:    breach.Condition.Threshold,
+			Key:          conditionKey(breach.Condition),
+			PendingSince: metav1.NewTime(breach.Since),
+			Sustained:    breach.Sustained,
+		})
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].Rule != pending[j].Rule {
+			return pending[i].Rule < pending[j].Rule
+		}
+		if pending[i].Type != pending[j].Type {
+			return pending[i].Type < pending[j].Type
+		}
+		if pending[i].Threshold != pending[j].Threshold {
+			return pending[i].Threshold < pending[j].Threshold
+		}
+		return pending[i].Key < pending[j].Key
+	})
+	return pending
+}
+
+// conditionDuration parses Condition.Duration, treating an empty duration as immediate
+func conditionDuration(condition remediationv1alpha1.Condition) (time.Duration, error) {
+	if condition.Duration == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(condition.Duration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q for %s condition: %w", condition.Duration, condition.Type, err)
+	}
+	return duration, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+func TestBreachTrackerObserve(t *testing.T) {
+	type observation struct {
+		at            time.Duration
+		met           bool
+		wantSustained bool
+		wantRemaining time.Duration
+	}
+	tests := []struct {
+		name         string
+		duration     string
+		observations []observation
+		wantErr      bool
+	}{
+		{
+			name:         "no duration is sustained at once",
+			observations: []observation{{at: 0, met: true, wantSustained: true}},
+		},
+		{
+			name:     "sustained once met for the duration",
+			duration: "5m",
+			observations: []observation{
+				{at: 0, met: true, wantRemaining: 5 * time.Minute},
+				{at: 2 * time.Minute, met: true, wantRemaining: 3 * time.Minute},
+				{at: 5 * time.Minute, met: true, wantSustained: true},
+				{at: 6 * time.Minute, met: true, wantSustained: true},
+			},
+		},
+		{
+			name:     "not met restarts the breach",
+			duration: "5m",
+			observations: []observation{
+				{at: 0, met: true, wantRemaining: 5 * time.Minute},
+				{at: 4 * time.Minute, met: false},
+				{at: 5 * time.Minute, met: true, wantRemaining: 5 * time.Minute},
+				{at: 9 * time.Minute, met: true, wantRemaining: time.Minute},
+			},
+		},
+		{
+			name:     "sustained breach ends when not met",
+			duration: "1m",
+			observations: []observation{
+				{at: 0, met: true, wantRemaining: time.Minute},
+				{at: time.Minute, met: true, wantSustained: true},
+				{at: 2 * time.Minute, met: false},
+				{at: 3 * time.Minute, met: true, wantRemaining: time.Minute},
+			},
+		},
+		{
+			name:         "invalid duration",
+			duration:     "5 minutes",
+			observations: []observation{{at: 0, met: true}},
+			wantErr:      true,
+		},
+	}
+
+	policy := types.NamespacedName{Namespace: "shop", Name: "cpu"}
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tracker BreachTracker
+			condition := remediationv1alpha1.Condition{Type: remediationv1alpha1.CPUUsage, Threshold: "80%", Duration: tt.duration}
+			for _, o := range tt.observations {
+				sustained, remaining, err := tracker.Observe(policy, "cpu", condition, o.met, start.Add(o.at))
+				if tt.wantErr != (err != nil) {
+					t.Fatalf("Observe() at %v error = %v, want error %v", o.at, err, tt.wantErr)
+				}
+				if sustained != o.wantSustained || remaining != o.wantRemaining {
+					t.Errorf("Observe() at %v = %v, %v, want %v, %v", o.at, sustained, remaining, o.wantSustained, o.wantRemaining)
+				}
+			}
+		})
+	}
+}
+
+func TestBreachTrackerObserveConditionsApart(t *testing.T) {
+	var tracker BreachTracker
+	policy := types.NamespacedName{Namespace: "shop", Name: "cpu"}
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	average := remediationv1alpha1.Condition{Type: remediationv1alpha1.CPUUsage, Threshold: "80%", Duration: "5m"}
+	peak := average
+	peak.Aggregation = remediationv1alpha1.AggregateMax
+
+	if _, _, err := tracker.Observe(policy, "cpu", average, true, start); err != nil {
+		t.Fatal(err)
+	}
+	sustained, remaining, err := tracker.Observe(policy, "cpu", peak, true, start.Add(5*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sustained || remaining != 5*time.Minute {
+		t.Errorf("condition differing in its aggregation shared the breach: %v, %v", sustained, remaining)
+	}
+
+	// Changing only the duration keeps the breach
+	average.Duration = "2m"
+	if sustained, _, _ := tracker.Observe(policy, "cpu", average, true, start.Add(3*time.Minute)); !sustained {
+		t.Error("changing the duration restarted the breach")
+	}
+}
@@ -0,0 +1,342 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+	"github.com/ikepcampbell/kubemedic/pkg/metrics"
+	"github.com/ikepcampbell/kubemedic/pkg/threshold"
+)
+
+// ConditionResult is the outcome of evaluating a single condition
+type ConditionResult struct {
+	Condition remediationv1alpha1.Condition
+	// Met is true when the observed value meets the threshold
+	Met bool
+	// Threshold is the parsed threshold of the condition
+	Threshold threshold.Threshold
+	// Observed is the aggregated value of the pods in the same unit as the threshold, or
+	// the number of pods meeting the threshold for CountOverThreshold conditions
+	Observed float64
+	// Pod is the name of the pod furthest over the threshold
+	Pod string
+}
+
+// ConditionEvaluator evaluates rule conditions against the target pods
+type ConditionEvaluator struct {
+	// sources measure the conditions that select them by name
+	sources map[string]metrics.MetricsSource
+	// restarts counts the restarts of pods within the windows of PodRestarts conditions
+	restarts *restartHistory
+}
+
+// NewConditionEvaluator creates an evaluator measuring conditions with the sources
+func NewConditionEvaluator(sources ...metrics.MetricsSource) *ConditionEvaluator {
+	e := &ConditionEvaluator{
+		sources:  make(map[string]metrics.MetricsSource, len(sources)),
+		restarts: newRestartHistory(),
+	}
+	for _, source := range sources {
+		e.AddSource(source)
+	}
+	return e
+}
+
+// AddSource makes a metrics source available to conditions, replacing the source of the
+// same name. Sources must be added before the evaluator is used.
+func (e *ConditionEvaluator) AddSource(source metrics.MetricsSource) {
+	if source == nil {
+		panic("metrics source cannot be nil")
+	}
+	e.sources[source.Name()] = source
+}
+
+// EvaluateRule evaluates every condition of the rule and reports whether all of them are met.
+// A rule without conditions is never met.
+func (e *ConditionEvaluator) EvaluateRule(
+	ctx context.Context,
+	pods []corev1.Pod,
+	rule remediationv1alpha1.Rule,
+) (bool, []ConditionResult, error) {
+	if len(rule.Conditions) == 0 {
+		return false, nil, nil
+	}
+
+	results := make([]ConditionResult, 0, len(rule.Conditions))
+	allMet := true
+	for _, condition := range rule.Conditions {
+		result, err := e.Evaluate(ctx, pods, condition)
+		if err != nil {
+			return false, results, fmt.Errorf("failed to evaluate %s condition: %w", condition.Type, err)
+		}
+		results = append(results, *result)
+		if !result.Met {
+			allMet = false
+		}
+	}
+
+	return allMet, results, nil
+}
+
+// Evaluate measures the condition's metric for each pod, aggregates the values as the
+// condition asks and compares the result against the threshold. Pods that cannot be
+// measured, such as pods that have only just started, are left out unless none can be.
+func (e *ConditionEvaluator) Evaluate(
+	ctx context.Context,
+	pods []corev1.Pod,
+	condition remediationv1alpha1.Condition,
+) (*ConditionResult, error) {
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods to evaluate")
+	}
+	log := log.FromContext(ctx)
+
+	threshold, err := threshold.ForCondition(condition.Type, condition.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	window, err := conditionWindow(condition, threshold)
+	if err != nil {
+		return nil, err
+	}
+	// Conditions read from the pod status have no source
+	var source metrics.MetricsSource
+	sourceName, err := metrics.ResolveSource(condition)
+	if err != nil {
+		return nil, err
+	}
+	if sourceName != "" {
+		var ok bool
+		if source, ok = e.sources[sourceName]; !ok {
+			return nil, fmt.Errorf("metrics source %s is not configured", sourceName)
+		}
+	}
+
+	now := time.Now()
+	values := make([]float64, 0, len(pods))
+	var worst string
+	var worstValue float64
+	var measureErr error
+	for i := range pods {
+		pod := &pods[i]
+		value, err := e.measure(ctx, source, pod, condition, threshold, window, now)
+		if err != nil {
+			if measureErr == nil {
+				measureErr = err
+			}
+			log.V(1).Info("Unable to measure pod", "pod", pod.Name, "condition_type", condition.Type, "error", err.Error())
+			continue
+		}
+		values = append(values, value)
+		if worst == "" || furtherOver(threshold, value, worstValue) {
+			worst, worstValue = pod.Name, value
+		}
+	}
+	if len(values) == 0 {
+		return nil, measureErr
+	}
+
+	observed, met := aggregate(condition, threshold, values)
+
+	log.V(1).Info("Evaluated condition",
+		"condition_type", condition.Type,
+		"threshold", condition.Threshold,
+		"aggregation", condition.Aggregation,
+		"pods", len(values),
+		"observed", observed,
+	)
+
+	return &ConditionResult{
+		Condition: condition,
+		Met:       met,
+		Threshold: threshold,
+		Observed:  observed,
+		Pod:       worst,
+	}, nil
+}
+
+// measure returns the condition's metric for one pod in the unit of the threshold, from
+// the source or, without one, from the pod's status
+func (e *ConditionEvaluator) measure(
+	ctx context.Context,
+	source metrics.MetricsSource,
+	pod *corev1.Pod,
+	condition remediationv1alpha1.Condition,
+	threshold threshold.Threshold,
+	window time.Duration,
+	now time.Time,
+) (float64, error) {
+	if source == nil {
+		return e.podStatusValue(pod, condition, threshold, window, now)
+	}
+
+	value, err := source.PodValue(ctx, pod, condition)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s from %s: %w", condition.Type, source.Name(), err)
+	}
+	if threshold.Percent {
+		switch condition.Type {
+		case remediationv1alpha1.CPUUsage:
+			return percentOf(value, pod, condition, corev1.ResourceCPU)
+		case remediationv1alpha1.MemoryUsage:
+			return percentOf(value, pod, condition, corev1.ResourceMemory)
+		}
+	}
+	return value, nil
+}
+
+// aggregate combines the values of the pods as the condition asks and reports whether
+// the result meets the threshold
+func aggregate(condition remediationv1alpha1.Condition, threshold threshold.Threshold, values []float64) (float64, bool) {
+	var observed float64
+	switch condition.Aggregation {
+	case remediationv1alpha1.AggregateCountOverThreshold:
+		for _, value := range values {
+			if threshold.Met(value) {
+				observed++
+			}
+		}
+		minCount := int32(1)
+		if condition.MinCount != nil {
+			minCount = *condition.MinCount
+		}
+		return observed, observed >= float64(minCount)
+
+	case remediationv1alpha1.AggregateMax:
+		observed = slices.Max(values)
+
+	case remediationv1alpha1.AggregateP95:
+		observed = percentile(values, 95)
+
+	default:
+		for _, value := range values {
+			observed += value
+		}
+		observed /= float64(len(values))
+	}
+	return observed, threshold.Met(observed)
+}
+
+// percentile returns the nearest-rank percentile of the values
+func percentile(values []float64, p float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// furtherOver reports whether value is further over the threshold than current
+func furtherOver(threshold threshold.Threshold, value, current float64) bool {
+	if threshold.Below() {
+		return value < current
+	}
+	return value > current
+}
+
+// percentOf converts an absolute usage into a percentage of the requests or limits of the
+// condition's container, or of all the pod's containers when it names none. The
+// condition's relativeTo chooses which; when it is unset, CPU is relative to requests and
+// memory to limits, with the other as the fallback.
+func percentOf(
+	usage float64,
+	pod *corev1.Pod,
+	condition remediationv1alpha1.Condition,
+	resourceName corev1.ResourceName,
+) (float64, error) {
+	limits := resourceName == corev1.ResourceMemory
+	if condition.RelativeTo != "" {
+		limits = condition.RelativeTo == remediationv1alpha1.RelativeToLimits
+	}
+	total, missing, err := resourceTotal(pod, condition.Container, resourceName, limits)
+	if err != nil {
+		return 0, err
+	}
+	if missing == "" {
+		return usage / total * 100, nil
+	}
+
+	if condition.RelativeTo != "" {
+		relativeTo := "requests"
+		if limits {
+			relativeTo = "limits"
+		}
+		return 0, fmt.Errorf("container %s of pod %s/%s has no %s %s to compute a percentage against",
+			missing, pod.Namespace, pod.Name, resourceName, relativeTo)
+	}
+	total, missing, _ = resourceTotal(pod, condition.Container, resourceName, !limits)
+	if missing != "" {
+		return 0, fmt.Errorf("container %s of pod %s/%s has no %s requests or limits to compute a percentage against",
+			missing, pod.Namespace, pod.Name, resourceName)
+	}
+	return usage / total * 100, nil
+}
+
+// resourceTotal sums the requests (or limits) of a resource over the container, or over all
+// the pod's containers when container is empty. CPU is returned in cores and memory in
+// bytes. missing names the first container that does not set the resource.
+func resourceTotal(
+	pod *corev1.Pod,
+	container string,
+	resourceName corev1.ResourceName,
+	limits bool,
+) (total float64, missing string, err error) {
+	found := false
+	for _, c := range podContainers(pod) {
+		if container != "" && c.Name != container {
+			continue
+		}
+		found = true
+		list := c.Resources.Requests
+		if limits {
+			list = c.Resources.Limits
+		}
+		quantity, ok := list[resourceName]
+		if !ok || quantity.IsZero() {
+			return 0, c.Name, nil
+		}
+		if resourceName == corev1.ResourceCPU {
+			total += float64(quantity.MilliValue()) / 1000.0
+		} else {
+			total += float64(quantity.Value())
+		}
+	}
+	if !found {
+		return 0, "", fmt.Errorf("pod %s/%s has no container %s", pod.Namespace, pod.Name, container)
+	}
+	return total, "", nil
+}
+
+// podContainers returns the containers that run for the pod's lifetime: its regular
+// containers and sidecars, the init containers that restart always
+func podContainers(pod *corev1.Pod) []corev1.Container {
+	containers := slices.Clone(pod.Spec.Containers)
+	for _, c := range pod.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			containers = append(containers, c)
+		}
+	}
+	return containers
+}
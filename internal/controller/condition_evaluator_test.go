@@ -0,0 +1,214 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"math"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+	"github.com/ikepcampbell/kubemedic/pkg/threshold"
+)
+
+func TestAggregate(t *testing.T) {
+	minCount := int32(2)
+	tests := []struct {
+		name         string
+		aggregation  string
+		minCount     *int32
+		threshold    string
+		values       []float64
+		wantObserved float64
+		wantMet      bool
+	}{
+		{name: "average met", threshold: ">=50", values: []float64{40, 60, 50}, wantObserved: 50, wantMet: true},
+		{name: "average not met", aggregation: remediationv1alpha1.AggregateAverage, threshold: ">=50", values: []float64{40, 60, 44}, wantObserved: 48},
+		{name: "max", aggregation: remediationv1alpha1.AggregateMax, threshold: ">=90", values: []float64{10, 95, 20}, wantObserved: 95, wantMet: true},
+		{name: "p95 of one value", aggregation: remediationv1alpha1.AggregateP95, threshold: ">=90", values: []float64{91}, wantObserved: 91, wantMet: true},
+		{name: "count over threshold defaults to one", aggregation: remediationv1alpha1.AggregateCountOverThreshold, threshold: ">=90", values: []float64{10, 95}, wantObserved: 1, wantMet: true},
+		{name: "count under min count", aggregation: remediationv1alpha1.AggregateCountOverThreshold, minCount: &minCount, threshold: ">=90", values: []float64{10, 95, 89}, wantObserved: 1},
+		{name: "count at min count", aggregation: remediationv1alpha1.AggregateCountOverThreshold, minCount: &minCount, threshold: ">=90", values: []float64{90, 95, 89}, wantObserved: 2, wantMet: true},
+		{name: "below threshold", aggregation: remediationv1alpha1.AggregateMax, threshold: "<=10", values: []float64{5, 8}, wantObserved: 8, wantMet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := threshold.Parse(tt.threshold, threshold.Number)
+			if err != nil {
+				t.Fatal(err)
+			}
+			condition := remediationv1alpha1.Condition{Aggregation: tt.aggregation, MinCount: tt.minCount}
+			observed, met := aggregate(condition, parsed, tt.values)
+			if math.Abs(observed-tt.wantObserved) > 1e-9 || met != tt.wantMet {
+				t.Errorf("aggregate(%v) = %v, %v, want %v, %v", tt.values, observed, met, tt.wantObserved, tt.wantMet)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	hundred := make([]float64, 100)
+	for i := range hundred {
+		hundred[i] = float64(100 - i)
+	}
+
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{name: "single value", values: []float64{7}, p: 95, want: 7},
+		{name: "p95 of twenty is the nineteenth", values: []float64{20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, p: 95, want: 19},
+		{name: "p95 of ten rounds up to the largest", values: []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, p: 95, want: 10},
+		{name: "p95 of a hundred unsorted", values: hundred, p: 95, want: 95},
+		{name: "p0 is the smallest", values: []float64{3, 1, 2}, p: 0, want: 1},
+		{name: "p100 is the largest", values: []float64{3, 1, 2}, p: 100, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.values, tt.p); got != tt.want {
+				t.Errorf("percentile(p%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentOf(t *testing.T) {
+	container := func(name string, requests, limits corev1.ResourceList) corev1.Container {
+		return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits}}
+	}
+	cpu := func(value string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(value)}
+	}
+	memory := func(value string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(value)}
+	}
+	always := corev1.ContainerRestartPolicyAlways
+
+	tests := []struct {
+		name       string
+		containers []corev1.Container
+		sidecars   []corev1.Container
+		condition  remediationv1alpha1.Condition
+		resource   corev1.ResourceName
+		usage      float64
+		want       float64
+		wantErr    bool
+	}{
+		{
+			name:       "CPU of requests by default",
+			containers: []corev1.Container{container("app", cpu("500m"), cpu("2"))},
+			resource:   corev1.ResourceCPU,
+			usage:      0.25,
+			want:       50,
+		},
+		{
+			name:       "CPU of limits",
+			containers: []corev1.Container{container("app", cpu("500m"), cpu("2"))},
+			condition:  remediationv1alpha1.Condition{RelativeTo: remediationv1alpha1.RelativeToLimits},
+			resource:   corev1.ResourceCPU,
+			usage:      0.5,
+			want:       25,
+		},
+		{
+			name:       "CPU falls back to limits",
+			containers: []corev1.Container{container("app", nil, cpu("1"))},
+			resource:   corev1.ResourceCPU,
+			usage:      0.5,
+			want:       50,
+		},
+		{
+			name:       "memory of limits by default",
+			containers: []corev1.Container{container("app", memory("256Mi"), memory("1Gi"))},
+			resource:   corev1.ResourceMemory,
+			usage:      512 * 1024 * 1024,
+			want:       50,
+		},
+		{
+			name:       "memory falls back to requests",
+			containers: []corev1.Container{container("app", memory("1Gi"), nil)},
+			resource:   corev1.ResourceMemory,
+			usage:      256 * 1024 * 1024,
+			want:       25,
+		},
+		{
+			name:       "no fallback when relative to is set",
+			containers: []corev1.Container{container("app", nil, cpu("1"))},
+			condition:  remediationv1alpha1.Condition{RelativeTo: remediationv1alpha1.RelativeToRequests},
+			resource:   corev1.ResourceCPU,
+			usage:      0.5,
+			wantErr:    true,
+		},
+		{
+			name:       "all containers including sidecars",
+			containers: []corev1.Container{container("app", cpu("750m"), nil)},
+			sidecars:   []corev1.Container{container("proxy", cpu("250m"), nil)},
+			resource:   corev1.ResourceCPU,
+			usage:      0.5,
+			want:       50,
+		},
+		{
+			name:       "named container",
+			containers: []corev1.Container{container("app", cpu("750m"), nil), container("log", cpu("250m"), nil)},
+			condition:  remediationv1alpha1.Condition{Container: "log"},
+			resource:   corev1.ResourceCPU,
+			usage:      0.125,
+			want:       50,
+		},
+		{
+			name:       "container without the resource",
+			containers: []corev1.Container{container("app", cpu("1"), nil), container("log", nil, nil)},
+			resource:   corev1.ResourceCPU,
+			usage:      0.5,
+			wantErr:    true,
+		},
+		{
+			name:       "missing container",
+			containers: []corev1.Container{container("app", cpu("1"), nil)},
+			condition:  remediationv1alpha1.Condition{Container: "log"},
+			resource:   corev1.ResourceCPU,
+			usage:      0.5,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-0", Namespace: "shop"},
+				Spec:       corev1.PodSpec{Containers: tt.containers},
+			}
+			for _, sidecar := range tt.sidecars {
+				sidecar.RestartPolicy = &always
+				pod.Spec.InitContainers = append(pod.Spec.InitContainers, sidecar)
+			}
+			got, err := percentOf(tt.usage, pod, tt.condition, tt.resource)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("percentOf() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("percentOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// lastRemediationAnnotation is set on every remediated target so that the cooldown is
+// honoured across all policies acting on the same resource
+const lastRemediationAnnotation = "kubemedic.io/last-remediation"
+
+// cooldownPeriod parses Spec.CooldownPeriod, treating an empty value as no cooldown
+func cooldownPeriod(policy *remediationv1alpha1.SelfRemediationPolicy) (time.Duration, error) {
+	if policy.Spec.CooldownPeriod == "" {
+		return 0, nil
+	}
+	cooldown, err := time.ParseDuration(policy.Spec.CooldownPeriod)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cooldown period %q: %w", policy.Spec.CooldownPeriod, err)
+	}
+	return cooldown, nil
+}
+
+// policyCooldownRemaining returns how long until the policy may take another action
+func policyCooldownRemaining(policy *remediationv1alpha1.SelfRemediationPolicy, now time.Time) time.Duration {
+	if policy.Status.NextEligibleTime == nil {
+		return 0
+	}
+	if remaining := policy.Status.NextEligibleTime.Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// targetCooldownRemaining returns how long until the target may be remediated again,
+// based on the last remediation recorded on the target by any policy
+func targetCooldownRemaining(target metav1.Object, cooldown time.Duration, now time.Time) time.Duration {
+	if cooldown <= 0 {
+		return 0
+	}
+	value, ok := target.GetAnnotations()[lastRemediationAnnotation]
+	if !ok {
+		return 0
+	}
+	last, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0
+	}
+	if remaining := last.Add(cooldown).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// markRemediated stamps the target with the time of the remediation being applied
+func markRemediated(target metav1.Object, now time.Time) {
+	annotations := target.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[lastRemediationAnnotation] = now.UTC().Format(time.RFC3339)
+	target.SetAnnotations(annotations)
+}
+
+// recordRemediation updates the policy and target cooldown bookkeeping in the policy status
+func recordRemediation(
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	action remediationv1alpha1.Action,
+	target client.Object,
+	cooldown time.Duration,
+	now time.Time,
+) {
+	actionTime := metav1.NewTime(now)
+	nextEligible := metav1.NewTime(now.Add(cooldown))
+
+	kind := targetKind(target)
+	policy.Status.LastRemediationAction = fmt.Sprintf("%s %s %s/%s",
+		action.Type, kind, target.GetNamespace(), target.GetName())
+	policy.Status.LastRemediationTime = &actionTime
+	policy.Status.NextEligibleTime = &nextEligible
+
+	upsertTargetCooldown(policy, kind, target, actionTime, nextEligible)
+}
+
+// upsertTargetCooldown records the cooldown window of a target in the policy status
+func upsertTargetCooldown(
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	kind string,
+	target metav1.Object,
+	lastAction metav1.Time,
+	nextEligible metav1.Time,
+) {
+	for i := range policy.Status.TargetCooldowns {
+		entry := &policy.Status.TargetCooldowns[i]
+		if entry.Kind == kind && entry.Namespace == target.GetNamespace() && entry.Name == target.GetName() {
+			entry.LastActionTime = lastAction
+			entry.NextEligibleTime = nextEligible
+			return
+		}
+	}
+	policy.Status.TargetCooldowns = append(policy.Status.TargetCooldowns, remediationv1alpha1.TargetCooldown{
+		Kind:             kind,
+		Name:             target.GetName(),
+		Namespace:        target.GetNamespace(),
+		LastActionTime:   lastAction,
+		NextEligibleTime: nextEligible,
+	})
+}
+
+// pruneTargetCooldowns drops targets whose cooldown has elapsed
+func pruneTargetCooldowns(policy *remediationv1alpha1.SelfRemediationPolicy, now time.Time) {
+	active := policy.Status.TargetCooldowns[:0]
+	for _, entry := range policy.Status.TargetCooldowns {
+		if entry.NextEligibleTime.After(now) {
+			active = append(active, entry)
+		}
+	}
+	if len(active) == 0 {
+		active = nil
+	}
+	policy.Status.TargetCooldowns = active
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+func TestCooldownPeriod(t *testing.T) {
+	tests := []struct {
+		name    string
+		period  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset", want: 0},
+		{name: "minutes", period: "5m", want: 5 * time.Minute},
+		{name: "compound", period: "1h30m", want: 90 * time.Minute},
+		{name: "invalid", period: "5 minutes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &remediationv1alpha1.SelfRemediationPolicy{
+				Spec: remediationv1alpha1.SelfRemediationPolicySpec{CooldownPeriod: tt.period},
+			}
+			got, err := cooldownPeriod(policy)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("cooldownPeriod(%q) error = %v, want error %v", tt.period, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("cooldownPeriod(%q) = %v, want %v", tt.period, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCooldownRemaining(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration) *metav1.Time {
+		at := metav1.NewTime(now.Add(offset))
+		return &at
+	}
+
+	tests := []struct {
+		name         string
+		nextEligible *metav1.Time
+		want         time.Duration
+	}{
+		{name: "never remediated"},
+		{name: "in cooldown", nextEligible: at(3 * time.Minute), want: 3 * time.Minute},
+		{name: "eligible now", nextEligible: at(0)},
+		{name: "cooldown elapsed", nextEligible: at(-time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &remediationv1alpha1.SelfRemediationPolicy{
+				Status: remediationv1alpha1.SelfRemediationPolicyStatus{NextEligibleTime: tt.nextEligible},
+			}
+			if got := policyCooldownRemaining(policy, now); got != tt.want {
+				t.Errorf("policyCooldownRemaining() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetCooldownRemaining(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		cooldown    time.Duration
+		want        time.Duration
+	}{
+		{name: "never remediated", cooldown: 5 * time.Minute},
+		{
+			name:        "in cooldown",
+			annotations: map[string]string{lastRemediationAnnotation: "2025-06-01T11:58:00Z"},
+			cooldown:    5 * time.Minute,
+			want:        3 * time.Minute,
+		},
+		{
+			name:        "cooldown ends now",
+			annotations: map[string]string{lastRemediationAnnotation: "2025-06-01T11:55:00Z"},
+			cooldown:    5 * time.Minute,
+		},
+		{
+			name:        "no cooldown",
+			annotations: map[string]string{lastRemediationAnnotation: "2025-06-01T11:59:00Z"},
+		},
+		{
+			name:        "unparsable annotation",
+			annotations: map[string]string{lastRemediationAnnotation: "yesterday"},
+			cooldown:    5 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := targetCooldownRemaining(target, tt.cooldown, now); got != tt.want {
+				t.Errorf("targetCooldownRemaining() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordRemediation(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := &remediationv1alpha1.SelfRemediationPolicy{}
+	action := remediationv1alpha1.Action{Type: remediationv1alpha1.ScaleUp}
+	checkout := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"}}
+	cart := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "cart", Namespace: "shop"}}
+
+	recordRemediation(policy, action, checkout, 5*time.Minute, now)
+	recordRemediation(policy, action, cart, 5*time.Minute, now.Add(time.Minute))
+	recordRemediation(policy, action, checkout, 5*time.Minute, now.Add(2*time.Minute))
+
+	if got, want := policy.Status.LastRemediationAction, "ScaleUp Deployment shop/checkout"; got != want {
+		t.Errorf("LastRemediationAction = %q, want %q", got, want)
+	}
+	if got, want := policy.Status.NextEligibleTime.Time, now.Add(7*time.Minute); !got.Equal(want) {
+		t.Errorf("NextEligibleTime = %v, want %v", got, want)
+	}
+	if len(policy.Status.TargetCooldowns) != 2 {
+		t.Fatalf("TargetCooldowns = %+v, want one entry per target", policy.Status.TargetCooldowns)
+	}
+	if entry := policy.Status.TargetCooldowns[0]; entry.Name != "checkout" || !entry.NextEligibleTime.Equal(&metav1.Time{Time: now.Add(7 * time.Minute)}) {
+		t.Errorf("cooldown of checkout = %+v, want it to end at %v", entry, now.Add(7*time.Minute))
+	}
+
+	pruneTargetCooldowns(policy, now.Add(6*time.Minute))
+	if len(policy.Status.TargetCooldowns) != 1 || policy.Status.TargetCooldowns[0].Name != "checkout" {
+		t.Errorf("after pruning TargetCooldowns = %+v, want only checkout", policy.Status.TargetCooldowns)
+	}
+	pruneTargetCooldowns(policy, now.Add(7*time.Minute))
+	if policy.Status.TargetCooldowns != nil {
+		t.Errorf("after the last cooldown TargetCooldowns = %+v, want none", policy.Status.TargetCooldowns)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// testScheme returns a scheme with the built-in and KubeMedic types
+func testScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := remediationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+// newTestClient returns a fake client holding the objects
+func newTestClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+	return fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(objects...).Build()
+}
+
+// testBackup returns a verified backup of obj, which must carry its TypeMeta, taken by the
+// policy but not yet owned by it
+func testBackup(t *testing.T, policy *remediationv1alpha1.SelfRemediationPolicy, obj client.Object, paths ...string) *remediationv1alpha1.RemediationBackup {
+	t.Helper()
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return &remediationv1alpha1.RemediationBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: policy.Name + "-backup", Namespace: policy.Namespace},
+		Spec: remediationv1alpha1.RemediationBackupSpec{
+			OriginalState: runtime.RawExtension{Raw: raw},
+			ResourceRef: remediationv1alpha1.ResourceReference{
+				APIGroup:  gvk.Group,
+				Kind:      gvk.Kind,
+				Name:      obj.GetName(),
+				Namespace: obj.GetNamespace(),
+			},
+			PolicyRef: remediationv1alpha1.ResourceReference{
+				APIGroup:  remediationv1alpha1.GroupVersion.Group,
+				Kind:      "SelfRemediationPolicy",
+				Name:      policy.Name,
+				Namespace: policy.Namespace,
+			},
+			OriginalLabels:      obj.GetLabels(),
+			OriginalAnnotations: obj.GetAnnotations(),
+			ModifiedPaths:       paths,
+		},
+		Status: remediationv1alpha1.RemediationBackupStatus{ContentHash: backupContentHash(raw)},
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// backupCount is the number of RemediationBackups per namespace and validity
+	backupCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubemedic_remediation_backups",
+			Help: "Number of RemediationBackups by namespace and validity",
+		},
+		[]string{"namespace", "valid"},
+	)
+
+	// backupBytes is the total size of the saved state of the RemediationBackups per namespace
+	backupBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubemedic_remediation_backup_bytes",
+			Help: "Total size in bytes of the original state held by RemediationBackups by namespace",
+		},
+		[]string{"namespace"},
+	)
+
+	// actionsTotal is the number of remediation actions taken
+	actionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubemedic_actions_total",
+			Help: "Number of remediation actions taken by namespace and action type",
+		},
+		[]string{"namespace", "action"},
+	)
+
+	// actionsRateLimited is the number of remediation actions suppressed by the rate limiter
+	actionsRateLimited = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubemedic_actions_rate_limited_total",
+			Help: "Number of remediation actions suppressed by the rate limiter by namespace and limiting scope",
+		},
+		[]string{"namespace", "scope"},
+	)
+)
+
+func init() {
+	// Served by the manager's metrics endpoint
+	metrics.Registry.MustRegister(backupCount, backupBytes, actionsTotal, actionsRateLimited)
+}
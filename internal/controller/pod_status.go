@@ -0,0 +1,229 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+	"github.com/ikepcampbell/kubemedic/pkg/threshold"
+)
+
+// Container state reasons reported by the kubelet
+const (
+	reasonCrashLoopBackOff = "CrashLoopBackOff"
+	reasonOOMKilled        = "OOMKilled"
+	reasonImagePullBackOff = "ImagePullBackOff"
+	reasonErrImagePull     = "ErrImagePull"
+)
+
+// restartPruneInterval is how often the restart history forgets what no window needs
+const restartPruneInterval = time.Minute
+
+// podStatusValue returns the value of a condition read from the pod's status. Restarts
+// and OOM kills are counted within the window when it is set; restarts are a rate per
+// second for rate thresholds.
+func (e *ConditionEvaluator) podStatusValue(
+	pod *corev1.Pod,
+	condition remediationv1alpha1.Condition,
+	threshold threshold.Threshold,
+	window time.Duration,
+	now time.Time,
+) (float64, error) {
+	switch condition.Type {
+	case remediationv1alpha1.PodRestarts:
+		if window == 0 {
+			return float64(podRestartCount(pod)), nil
+		}
+		restarts := float64(e.restarts.restartsWithin(pod, window, now))
+		if threshold.IsRate() {
+			return restarts / window.Seconds(), nil
+		}
+		return restarts, nil
+
+	case remediationv1alpha1.CrashLoopBackOff:
+		return float64(containersWaiting(pod, reasonCrashLoopBackOff)), nil
+
+	case remediationv1alpha1.ImagePullBackOff:
+		return float64(containersWaiting(pod, reasonImagePullBackOff, reasonErrImagePull)), nil
+
+	case remediationv1alpha1.OOMKilled:
+		var since time.Time
+		if window > 0 {
+			since = now.Add(-window)
+		}
+		return float64(containersOOMKilled(pod, since)), nil
+
+	default:
+		return 0, fmt.Errorf("unsupported condition type: %s", condition.Type)
+	}
+}
+
+// conditionWindow returns the window a condition counts restarts or OOM kills in: its
+// window, or for restart rates the period of the threshold. It is zero when neither is set.
+func conditionWindow(condition remediationv1alpha1.Condition, threshold threshold.Threshold) (time.Duration, error) {
+	if condition.Window != "" {
+		window, err := time.ParseDuration(condition.Window)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q: %w", condition.Window, err)
+		}
+		if window <= 0 {
+			return 0, fmt.Errorf("window must be positive")
+		}
+		return window, nil
+	}
+	if condition.Type == remediationv1alpha1.PodRestarts && threshold.IsRate() {
+		return threshold.Per, nil
+	}
+	return 0, nil
+}
+
+// containerStatuses returns the statuses of the pod's init and regular containers
+func containerStatuses(pod *corev1.Pod) []corev1.ContainerStatus {
+	return slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses)
+}
+
+// containersWaiting counts the pod's containers waiting for one of the reasons
+func containersWaiting(pod *corev1.Pod, reasons ...string) int {
+	var count int
+	for _, status := range containerStatuses(pod) {
+		if status.State.Waiting != nil && slices.Contains(reasons, status.State.Waiting.Reason) {
+			count++
+		}
+	}
+	return count
+}
+
+// containersOOMKilled counts the pod's containers whose current or last termination was
+// an OOM kill that finished after since
+func containersOOMKilled(pod *corev1.Pod, since time.Time) int {
+	var count int
+	for _, status := range containerStatuses(pod) {
+		for _, terminated := range []*corev1.ContainerStateTerminated{
+			status.State.Terminated,
+			status.LastTerminationState.Terminated,
+		} {
+			if terminated != nil && terminated.Reason == reasonOOMKilled && terminated.FinishedAt.After(since) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// podRestartCount sums the restart counts of all containers in the pod
+func podRestartCount(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+	return restarts
+}
+
+// restartSample is a pod's restart count from the time the controller observed it
+type restartSample struct {
+	at       time.Time
+	restarts int32
+}
+
+// podRestarts are the restart counts observed for one pod, oldest first
+type podRestarts struct {
+	samples  []restartSample
+	lastSeen time.Time
+}
+
+// restartHistory remembers when the restart counts of pods changed, so that restarts can
+// be counted within a window. The history is kept in memory: after the controller starts,
+// a pod's restarts are counted from when it is first observed, unless the pod itself
+// started within the window.
+type restartHistory struct {
+	mu   sync.Mutex
+	pods map[types.UID]*podRestarts
+	// retention is the longest window asked for; older samples are forgotten
+	retention time.Duration
+	lastPrune time.Time
+}
+
+func newRestartHistory() *restartHistory {
+	return &restartHistory{pods: make(map[types.UID]*podRestarts)}
+}
+
+// restartsWithin records the pod's current restart count and returns how many times its
+// containers restarted within the window before now
+func (h *restartHistory) restartsWithin(pod *corev1.Pod, window time.Duration, now time.Time) int32 {
+	current := podRestartCount(pod)
+	start := now.Add(-window)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.retention = max(h.retention, window)
+	h.prune(now)
+
+	entry, ok := h.pods[pod.UID]
+	if !ok {
+		entry = &podRestarts{}
+		h.pods[pod.UID] = entry
+	}
+	entry.lastSeen = now
+	if n := len(entry.samples); n == 0 || entry.samples[n-1].restarts != current {
+		entry.samples = append(entry.samples, restartSample{at: now, restarts: current})
+	}
+
+	// A pod that started within the window restarted only within it
+	if pod.Status.StartTime != nil && !pod.Status.StartTime.Time.Before(start) {
+		return current
+	}
+	// Otherwise count from the last sample before the window, or from the first
+	// observation when the pod has not been observed for the whole window
+	baseline := entry.samples[0].restarts
+	for _, sample := range entry.samples {
+		if sample.at.After(start) {
+			break
+		}
+		baseline = sample.restarts
+	}
+	return current - baseline
+}
+
+// prune forgets pods that have not been observed within the retention, and the samples
+// of the others that no window reaches back to
+func (h *restartHistory) prune(now time.Time) {
+	if now.Sub(h.lastPrune) < restartPruneInterval {
+		return
+	}
+	h.lastPrune = now
+	cutoff := now.Add(-h.retention)
+	for uid, entry := range h.pods {
+		if entry.lastSeen.Before(cutoff) {
+			delete(h.pods, uid)
+			continue
+		}
+		// Keep the last sample before the cutoff as the baseline of the longest window
+		keep := 0
+		for keep+1 < len(entry.samples) && !entry.samples[keep+1].at.After(cutoff) {
+			keep++
+		}
+		entry.samples = slices.Delete(entry.samples, 0, keep)
+	}
+}
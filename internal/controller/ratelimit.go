@@ -0,0 +1,240 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ikepcampbell/kubemedic/pkg/safety"
+)
+
+const (
+	// rateLimiterConfigMap is the name of the ConfigMap holding the rate limiter state
+	rateLimiterConfigMap = "kubemedic-rate-limiter"
+	// rateLimiterStateKey is the ConfigMap key holding the token buckets
+	rateLimiterStateKey = "buckets"
+	// rateLimitWindow is the period the action limits refer to
+	rateLimitWindow = time.Hour
+)
+
+// rateLimitScope is one of the scopes a remediation action is limited in
+type rateLimitScope struct {
+	// name is global, namespace, policy or target
+	name string
+	// key identifies the bucket of the scope
+	key string
+	// limit is the number of actions per hour, zero for no limit
+	limit int32
+}
+
+// rateLimitScopes returns the scopes an action of the policy on the target is limited in
+func rateLimitScopes(settings *safety.Settings, policy, target client.Object) []rateLimitScope {
+	return []rateLimitScope{
+		{name: "global", key: "global", limit: settings.ClusterLimits.MaxActionsPerHour},
+		{
+			name:  "namespace",
+			key:   "namespace/" + policy.GetNamespace(),
+			limit: settings.Limits.MaxActionsPerHourPerNamespace,
+		},
+		{
+			name:  "policy",
+			key:   fmt.Sprintf("policy/%s/%s", policy.GetNamespace(), policy.GetName()),
+			limit: settings.Limits.MaxActionsPerHourPerPolicy,
+		},
+		{
+			name:  "target",
+			key:   fmt.Sprintf("target/%s/%s/%s", targetKind(target), target.GetNamespace(), target.GetName()),
+			limit: settings.Limits.MaxActionsPerHourPerTarget,
+		},
+	}
+}
+
+// tokenBucket holds up to limit tokens and regains limit tokens per hour
+type tokenBucket struct {
+	Tokens  float64   `json:"tokens"`
+	Updated time.Time `json:"updated"`
+}
+
+// refill adds the tokens regained since the bucket was last updated
+func (b *tokenBucket) refill(limit int32, now time.Time) {
+	capacity := float64(limit)
+	if elapsed := now.Sub(b.Updated); elapsed > 0 {
+		b.Tokens += capacity * elapsed.Seconds() / rateLimitWindow.Seconds()
+	}
+	// The limit may have been lowered since
+	b.Tokens = math.Min(b.Tokens, capacity)
+	b.Updated = now
+}
+
+// ActionRateLimiter limits how often remediation actions are taken, with a token bucket
+// per scope shared by all policies. The buckets are saved in a ConfigMap so that a new
+// leader continues where the previous one stopped.
+type ActionRateLimiter struct {
+	client client.Client
+	// reader reads the state without caching ConfigMaps
+	reader client.Reader
+	key    types.NamespacedName
+
+	mu      sync.Mutex
+	loaded  bool
+	buckets map[string]*tokenBucket
+}
+
+// NewActionRateLimiter creates a rate limiter keeping its state in the given namespace
+func NewActionRateLimiter(c client.Client, reader client.Reader, namespace string) *ActionRateLimiter {
+	if c == nil {
+		panic("client cannot be nil")
+	}
+	if reader == nil {
+		panic("reader cannot be nil")
+	}
+	return &ActionRateLimiter{
+		client:  c,
+		reader:  reader,
+		key:     types.NamespacedName{Namespace: namespace, Name: rateLimiterConfigMap},
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Check returns the first scope with no token left for another action, or nil when the
+// action may be taken
+func (l *ActionRateLimiter) Check(ctx context.Context, scopes []rateLimitScope, now time.Time) (*rateLimitScope, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.load(ctx); err != nil {
+		return nil, err
+	}
+	for i, scope := range scopes {
+		if scope.limit <= 0 {
+			continue
+		}
+		if l.bucket(scope, now).Tokens < 1 {
+			return &scopes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Take uses a token of every scope for an action that was taken and saves the state
+func (l *ActionRateLimiter) Take(ctx context.Context, scopes []rateLimitScope, now time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.load(ctx); err != nil {
+		return err
+	}
+	for _, scope := range scopes {
+		if scope.limit <= 0 {
+			continue
+		}
+		bucket := l.bucket(scope, now)
+		bucket.Tokens = math.Max(bucket.Tokens-1, 0)
+	}
+	return l.save(ctx, now)
+}
+
+// bucket returns the refilled bucket of the scope, creating a full one when there is none
+func (l *ActionRateLimiter) bucket(scope rateLimitScope, now time.Time) *tokenBucket {
+	bucket, ok := l.buckets[scope.key]
+	if !ok {
+		bucket = &tokenBucket{Tokens: float64(scope.limit), Updated: now}
+		l.buckets[scope.key] = bucket
+	}
+	bucket.refill(scope.limit, now)
+	return bucket
+}
+
+// load reads the buckets saved by this or a previous leader, once
+func (l *ActionRateLimiter) load(ctx context.Context) error {
+	if l.loaded {
+		return nil
+	}
+
+	var configMap corev1.ConfigMap
+	if err := l.reader.Get(ctx, l.key, &configMap); err != nil {
+		if errors.IsNotFound(err) {
+			l.loaded = true
+			return nil
+		}
+		return fmt.Errorf("failed to get rate limiter state: %w", err)
+	}
+	if data, ok := configMap.Data[rateLimiterStateKey]; ok {
+		buckets := make(map[string]*tokenBucket)
+		if err := json.Unmarshal([]byte(data), &buckets); err != nil {
+			// Starting over with full buckets is better than never remediating again
+			log.FromContext(ctx).Error(err, "Discarding invalid rate limiter state", "configmap", l.key.String())
+		} else {
+			l.buckets = buckets
+		}
+	}
+	l.loaded = true
+	return nil
+}
+
+// save writes the buckets to the ConfigMap, leaving out those that have refilled completely
+func (l *ActionRateLimiter) save(ctx context.Context, now time.Time) error {
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.Updated) >= rateLimitWindow {
+			delete(l.buckets, key)
+		}
+	}
+	data, err := json.Marshal(l.buckets)
+	if err != nil {
+		return fmt.Errorf("failed to encode rate limiter state: %w", err)
+	}
+
+	var configMap corev1.ConfigMap
+	if err := l.reader.Get(ctx, l.key, &configMap); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get rate limiter state: %w", err)
+		}
+		configMap = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      l.key.Name,
+				Namespace: l.key.Namespace,
+				Labels:    map[string]string{"app.kubernetes.io/name": "kubemedic"},
+			},
+			Data: map[string]string{rateLimiterStateKey: string(data)},
+		}
+		if err := l.client.Create(ctx, &configMap); err != nil {
+			return fmt.Errorf("failed to save rate limiter state: %w", err)
+		}
+		return nil
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[rateLimiterStateKey] = string(data)
+	if err := l.client.Update(ctx, &configMap); err != nil {
+		return fmt.Errorf("failed to save rate limiter state: %w", err)
+	}
+	return nil
+}
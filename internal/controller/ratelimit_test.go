@@ -0,0 +1,57 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketRefill(t *testing.T) {
+	updated := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		tokens  float64
+		limit   int32
+		elapsed time.Duration
+		want    float64
+	}{
+		{name: "no time passed", tokens: 2, limit: 10, want: 2},
+		{name: "quarter of the window", tokens: 0, limit: 12, elapsed: 15 * time.Minute, want: 3},
+		{name: "partial token", tokens: 1, limit: 6, elapsed: 5 * time.Minute, want: 1.5},
+		{name: "capped at the limit", tokens: 8, limit: 10, elapsed: time.Hour, want: 10},
+		{name: "full after idle window", tokens: 0, limit: 5, elapsed: 3 * time.Hour, want: 5},
+		{name: "limit lowered", tokens: 10, limit: 4, elapsed: time.Minute, want: 4},
+		{name: "clock went back", tokens: 2, limit: 10, elapsed: -time.Minute, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket := &tokenBucket{Tokens: tt.tokens, Updated: updated}
+			now := updated.Add(tt.elapsed)
+			bucket.refill(tt.limit, now)
+			if math.Abs(bucket.Tokens-tt.want) > 1e-9 {
+				t.Errorf("refill() left %v tokens, want %v", bucket.Tokens, tt.want)
+			}
+			if !bucket.Updated.Equal(now) {
+				t.Errorf("refill() set updated to %v, want %v", bucket.Updated, now)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+const (
+	// backupValidationInterval is how often a backup is revalidated
+	backupValidationInterval = 10 * time.Minute
+	// backupHashGracePeriod gives the policy controller time to record the content hash of
+	// a backup it has just created before a missing hash counts as invalid
+	backupHashGracePeriod = time.Minute
+)
+
+// RemediationBackupReconciler validates RemediationBackups and deletes them once their TTL expires
+type RemediationBackupReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// NewRemediationBackupReconciler creates a new RemediationBackupReconciler
+func NewRemediationBackupReconciler(
+	client client.Client,
+	scheme *runtime.Scheme,
+	recorder record.EventRecorder,
+) *RemediationBackupReconciler {
+	if client == nil {
+		panic("client cannot be nil")
+	}
+	if scheme == nil {
+		panic("scheme cannot be nil")
+	}
+	if recorder == nil {
+		panic("recorder cannot be nil")
+	}
+
+	return &RemediationBackupReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		Recorder: recorder,
+	}
+}
+
+// Reconcile checks the integrity of a backup and that the resource it was taken from can
+// still be restored from it, records the result in the backup status, and deletes the
+// backup once it has expired
+func (r *RemediationBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	defer r.recordBackupMetrics(ctx, req.Namespace)
+
+	var backup remediationv1alpha1.RemediationBackup
+	if err := r.Get(ctx, req.NamespacedName, &backup); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get RemediationBackup")
+		return ctrl.Result{}, err
+	}
+	if !backup.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	if backup.Status.ContentHash == "" && now.Sub(backup.CreationTimestamp.Time) < backupHashGracePeriod {
+		return ctrl.Result{RequeueAfter: backupHashGracePeriod}, nil
+	}
+
+	validationErrors, inUse, err := r.validateBackup(ctx, &backup)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if expiresAt, ok := backupExpiry(&backup); ok && !now.Before(expiresAt) {
+		if inUse {
+			// The backup still holds the original state of a pending reversion
+			log.V(1).Info("Keeping expired backup of a pending reversion")
+		} else {
+			log.Info("Deleting expired backup", "expired_at", expiresAt)
+			if err := r.Delete(ctx, &backup); err != nil && !errors.IsNotFound(err) {
+				return ctrl.Result{}, fmt.Errorf("failed to delete expired backup: %w", err)
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+
+	wasInvalid := backup.Status.LastValidationTime != nil && !backup.Status.IsValid
+	backup.Status.IsValid = len(validationErrors) == 0
+	backup.Status.ValidationErrors = validationErrors
+	backup.Status.LastValidationTime = &metav1.Time{Time: now}
+	if err := r.Status().Update(ctx, &backup); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to update backup status: %w", err)
+	}
+
+	if !backup.Status.IsValid && !wasInvalid {
+		log.Info("Backup failed validation", "errors", validationErrors)
+		r.Recorder.Eventf(&backup, corev1.EventTypeWarning, "BackupInvalid",
+			"Backup of %s %s/%s cannot be restored: %v",
+			backup.Spec.ResourceRef.Kind, backup.Spec.ResourceRef.Namespace, backup.Spec.ResourceRef.Name,
+			validationErrors)
+	}
+
+	requeue := backupValidationInterval
+	if expiresAt, ok := backupExpiry(&backup); ok && expiresAt.Sub(now) < requeue {
+		requeue = max(expiresAt.Sub(now), time.Second)
+	}
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+// backupExpiry returns when the backup's TTL runs out, or false when it has no TTL
+func backupExpiry(backup *remediationv1alpha1.RemediationBackup) (time.Time, bool) {
+	if backup.Spec.TTL == nil {
+		return time.Time{}, false
+	}
+	created := backup.Spec.BackupTime.Time
+	if created.IsZero() {
+		created = backup.CreationTimestamp.Time
+	}
+	return created.Add(backup.Spec.TTL.Duration), true
+}
+
+// validateBackup returns the reasons the backup cannot be restored, and whether the
+// referenced resource is still pointed at the backup by a pending reversion
+func (r *RemediationBackupReconciler) validateBackup(
+	ctx context.Context,
+	backup *remediationv1alpha1.RemediationBackup,
+) ([]string, bool, error) {
+	var validationErrors []string
+	if err := verifyBackupContent(backup); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	}
+	if len(backup.Spec.OriginalState.Raw) == 0 {
+		return validationErrors, false, nil
+	}
+
+	var original unstructured.Unstructured
+	if err := original.UnmarshalJSON(backup.Spec.OriginalState.Raw); err != nil {
+		return append(validationErrors, fmt.Sprintf("original state cannot be decoded: %v", err)), false, nil
+	}
+	gvk := original.GroupVersionKind()
+	ref := backup.Spec.ResourceRef
+	if gvk.Group != ref.APIGroup || gvk.Kind != ref.Kind {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"original state is a %s, but the backup references a %s", gvk.GroupKind(), ref.Kind))
+	}
+	if original.GetNamespace() != ref.Namespace || original.GetName() != ref.Name {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"original state is of %s/%s, but the backup references %s/%s",
+			original.GetNamespace(), original.GetName(), ref.Namespace, ref.Name))
+	}
+
+	// The original state must still match the schema the controller restores it with
+	decoded, err := r.Scheme.New(gvk)
+	if err != nil {
+		return append(validationErrors, fmt.Sprintf("kind %s is not supported", gvk)), false, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(backup.Spec.OriginalState.Raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(decoded); err != nil {
+		validationErrors = append(validationErrors, fmt.Sprintf("original state does not match the %s schema: %v", gvk.Kind, err))
+	}
+
+	live, err := r.Scheme.New(gvk)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create %s: %w", gvk.Kind, err)
+	}
+	current, ok := live.(client.Object)
+	if !ok {
+		return append(validationErrors, fmt.Sprintf("kind %s is not a Kubernetes object", gvk)), false, nil
+	}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, current); err != nil {
+		if errors.IsNotFound(err) {
+			return append(validationErrors, "referenced resource no longer exists"), false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get %s %s/%s: %w", ref.Kind, ref.Namespace, ref.Name, err)
+	}
+	if current.GetUID() != original.GetUID() {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"referenced resource was recreated: UID %s does not match backup UID %s", current.GetUID(), original.GetUID()))
+	}
+
+	inUse := hasPendingReversion(current) && current.GetAnnotations()[backupAnnotation] == backup.Name
+	return validationErrors, inUse, nil
+}
+
+// recordBackupMetrics publishes the number and total size of the backups in the namespace
+func (r *RemediationBackupReconciler) recordBackupMetrics(ctx context.Context, namespace string) {
+	var backups remediationv1alpha1.RemediationBackupList
+	if err := r.List(ctx, &backups, client.InNamespace(namespace)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list backups for metrics")
+		return
+	}
+
+	counts := map[bool]float64{true: 0, false: 0}
+	var size int64
+	for _, backup := range backups.Items {
+		if !backup.DeletionTimestamp.IsZero() {
+			continue
+		}
+		counts[backup.Status.IsValid]++
+		size += backup.Status.BackupSizeBytes
+	}
+	for valid, count := range counts {
+		backupCount.WithLabelValues(namespace, strconv.FormatBool(valid)).Set(count)
+	}
+	backupBytes.WithLabelValues(namespace).Set(float64(size))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RemediationBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&remediationv1alpha1.RemediationBackup{}).
+		Complete(r)
+}
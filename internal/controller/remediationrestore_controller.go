@@ -0,0 +1,445 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+	"github.com/ikepcampbell/kubemedic/pkg/safety"
+)
+
+// kubemedicKeyPrefix marks the labels and annotations KubeMedic manages itself; changes to
+// them are not conflicts
+const kubemedicKeyPrefix = "kubemedic.io/"
+
+// systemAnnotationPrefixes are annotations owned by other controllers. A restore keeps
+// their current values and does not treat changes to them as conflicts.
+var systemAnnotationPrefixes = []string{"deployment.kubernetes.io/"}
+
+// restartedAtPath is the restart stamp of a workload's pod template, which a restore keeps
+// unless it restores the whole template
+var restartedAtPath = []string{"spec", "template", "metadata", "annotations", restartedAtAnnotation}
+
+// listIndex matches list indices in field paths, which are compared as wildcards
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+// RemediationRestoreReconciler applies RemediationRestores, restoring resources to the
+// state saved in a RemediationBackup
+type RemediationRestoreReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// NewRemediationRestoreReconciler creates a new RemediationRestoreReconciler
+func NewRemediationRestoreReconciler(
+	client client.Client,
+	scheme *runtime.Scheme,
+	recorder record.EventRecorder,
+) *RemediationRestoreReconciler {
+	if client == nil {
+		panic("client cannot be nil")
+	}
+	if scheme == nil {
+		panic("scheme cannot be nil")
+	}
+	if recorder == nil {
+		panic("recorder cannot be nil")
+	}
+
+	return &RemediationRestoreReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		Recorder: recorder,
+	}
+}
+
+// Reconcile applies a restore once. Its outcome is recorded in the restore status, and a
+// restore with a phase is never applied again.
+func (r *RemediationRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var restore remediationv1alpha1.RemediationRestore
+	if err := r.Get(ctx, req.NamespacedName, &restore); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get RemediationRestore")
+		return ctrl.Result{}, err
+	}
+	if restore.Status.Phase != "" || !restore.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	var backup remediationv1alpha1.RemediationBackup
+	if err := r.Get(ctx, types.NamespacedName{Namespace: restore.Namespace, Name: restore.Spec.BackupName}, &backup); err != nil {
+		if errors.IsNotFound(err) {
+			return r.finish(ctx, &restore, remediationv1alpha1.RestoreFailed,
+				fmt.Sprintf("backup %s not found", restore.Spec.BackupName), nil)
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get backup %s: %w", restore.Spec.BackupName, err)
+	}
+
+	// Restores write with the controller's permissions, so only backups KubeMedic took are
+	// accepted, and only for resources remediation may change
+	err := r.checkRestoreAllowed(ctx, &backup)
+	var resource string
+	var conflicts []string
+	applied := false
+	if err == nil {
+		resource, conflicts, applied, err = restoreBackup(ctx, r.Client, &backup, backup.Spec.ModifiedPaths, restore.Spec.Force)
+	}
+	var failure *restoreFailure
+	switch {
+	case goerrors.As(err, &failure):
+		return r.finish(ctx, &restore, remediationv1alpha1.RestoreFailed, failure.Error(), conflicts)
+	case errors.IsConflict(err):
+		// Changed while we compared; check again against the new state
+		return ctrl.Result{Requeue: true}, nil
+	case err != nil:
+		return ctrl.Result{}, err
+	case !applied:
+		return r.finish(ctx, &restore, remediationv1alpha1.RestoreConflict,
+			fmt.Sprintf("%s was changed by others since backup %s was taken; set force to overwrite", resource, backup.Name),
+			conflicts)
+	}
+
+	message := fmt.Sprintf("Restored %s from backup %s", resource, backup.Name)
+	if len(conflicts) > 0 {
+		message += fmt.Sprintf(", overwriting %d changed fields", len(conflicts))
+	}
+	log.Info("Restored resource from backup", "resource", resource, "backup", backup.Name)
+	return r.finish(ctx, &restore, remediationv1alpha1.RestoreSucceeded, message, conflicts)
+}
+
+// checkRestoreAllowed returns a restoreFailure when the backup was not taken by a policy of
+// its namespace or fails verification, or when the resource it restores may not be changed
+// by remediation: it is protected, or its namespace is denied, not allowed or excluded.
+func (r *RemediationRestoreReconciler) checkRestoreAllowed(
+	ctx context.Context,
+	backup *remediationv1alpha1.RemediationBackup,
+) error {
+	owner := metav1.GetControllerOf(backup)
+	if owner == nil || owner.Kind != "SelfRemediationPolicy" ||
+		owner.APIVersion != remediationv1alpha1.GroupVersion.String() || owner.Name != backup.Spec.PolicyRef.Name {
+		return &restoreFailure{fmt.Sprintf("backup %s was not taken by a SelfRemediationPolicy", backup.Name)}
+	}
+	var policy remediationv1alpha1.SelfRemediationPolicy
+	if err := r.Get(ctx, types.NamespacedName{Namespace: backup.Namespace, Name: owner.Name}, &policy); err != nil {
+		if errors.IsNotFound(err) {
+			return &restoreFailure{fmt.Sprintf("policy %s that took backup %s no longer exists", owner.Name, backup.Name)}
+		}
+		return fmt.Errorf("failed to get policy %s: %w", owner.Name, err)
+	}
+	if policy.UID != owner.UID {
+		return &restoreFailure{fmt.Sprintf("backup %s was not taken by policy %s", backup.Name, owner.Name)}
+	}
+	if err := verifyBackupContent(backup); err != nil {
+		return &restoreFailure{fmt.Sprintf("backup %s failed verification: %v", backup.Name, err)}
+	}
+
+	var original unstructured.Unstructured
+	if err := original.UnmarshalJSON(backup.Spec.OriginalState.Raw); err != nil {
+		return &restoreFailure{fmt.Sprintf("original state of backup %s cannot be decoded: %v", backup.Name, err)}
+	}
+	ref := backup.Spec.ResourceRef
+	if original.GetKind() != ref.Kind || original.GetName() != ref.Name || original.GetNamespace() != ref.Namespace {
+		return &restoreFailure{fmt.Sprintf("original state of backup %s is not of %s %s/%s",
+			backup.Name, ref.Kind, ref.Namespace, ref.Name)}
+	}
+
+	settings, err := safety.Load(ctx, r.Client, backup.Namespace)
+	var invalidConfig *safety.InvalidConfigError
+	if err != nil && !goerrors.As(err, &invalidConfig) {
+		return err
+	}
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(original.GroupVersionKind())
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, current); err != nil {
+		if errors.IsNotFound(err) {
+			return &restoreFailure{fmt.Sprintf("%s %s/%s no longer exists", ref.Kind, ref.Namespace, ref.Name)}
+		}
+		return fmt.Errorf("failed to get %s %s/%s: %w", ref.Kind, ref.Namespace, ref.Name, err)
+	}
+	if err := checkProtection(ctx, r.Client, settings, backup.Namespace, current); err != nil {
+		return &restoreFailure{fmt.Sprintf("restore not allowed: %v", err)}
+	}
+	return nil
+}
+
+// finish records the outcome of the restore in its status and as an Event
+func (r *RemediationRestoreReconciler) finish(
+	ctx context.Context,
+	restore *remediationv1alpha1.RemediationRestore,
+	phase string,
+	message string,
+	conflicts []string,
+) (ctrl.Result, error) {
+	restore.Status.Phase = phase
+	restore.Status.Message = message
+	restore.Status.Conflicts = conflicts
+	restore.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	if err := r.Status().Update(ctx, restore); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update restore status: %w", err)
+	}
+
+	switch phase {
+	case remediationv1alpha1.RestoreSucceeded:
+		r.Recorder.Event(restore, corev1.EventTypeNormal, "Restored", message)
+	case remediationv1alpha1.RestoreConflict:
+		r.Recorder.Eventf(restore, corev1.EventTypeWarning, "RestoreConflict", "%s: %s",
+			message, strings.Join(conflicts, ", "))
+	default:
+		r.Recorder.Event(restore, corev1.EventTypeWarning, "RestoreFailed", message)
+	}
+	return ctrl.Result{}, nil
+}
+
+// restoreFailure is a restore that cannot succeed; retrying it will not help
+type restoreFailure struct {
+	message string
+}
+
+func (e *restoreFailure) Error() string {
+	return e.message
+}
+
+// restoreBackup applies the original state saved in the backup to the resource it was
+// taken from. Fields that differ from the original state outside modifiedPaths were changed
+// by others; they are returned as conflicts and, unless force is set, the restore is not
+// applied. It returns the resource as "Kind namespace/name" and whether it was restored.
+func restoreBackup(
+	ctx context.Context,
+	c client.Client,
+	backup *remediationv1alpha1.RemediationBackup,
+	modifiedPaths []string,
+	force bool,
+) (string, []string, bool, error) {
+	ref := backup.Spec.ResourceRef
+	resource := fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+	if err := verifyBackupContent(backup); err != nil {
+		return resource, nil, false, &restoreFailure{fmt.Sprintf("backup %s failed verification: %v", backup.Name, err)}
+	}
+
+	var original unstructured.Unstructured
+	if err := original.UnmarshalJSON(backup.Spec.OriginalState.Raw); err != nil {
+		return resource, nil, false, &restoreFailure{
+			fmt.Sprintf("original state of backup %s cannot be decoded: %v", backup.Name, err)}
+	}
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(original.GroupVersionKind())
+	if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, current); err != nil {
+		if errors.IsNotFound(err) {
+			return resource, nil, false, &restoreFailure{fmt.Sprintf("%s no longer exists", resource)}
+		}
+		return resource, nil, false, fmt.Errorf("failed to get %s: %w", resource, err)
+	}
+	if current.GetUID() != original.GetUID() {
+		return resource, nil, false, &restoreFailure{
+			fmt.Sprintf("%s was recreated since backup %s was taken", resource, backup.Name)}
+	}
+
+	conflicts := restoreConflicts(backup, &original, current, modifiedPaths)
+	if len(conflicts) > 0 && !force {
+		return resource, conflicts, false, nil
+	}
+
+	restartedAt, restarted, _ := unstructured.NestedString(current.Object, restartedAtPath...)
+	applyOriginalState(backup, &original, current)
+	if restarted && !pathModified(templatePath, modifiedPaths) {
+		// Restoring an earlier stamp would roll the workload once more
+		if err := unstructured.SetNestedField(current.Object, restartedAt, restartedAtPath...); err != nil {
+			return resource, conflicts, false, fmt.Errorf("failed to keep restart stamp of %s: %w", resource, err)
+		}
+	}
+	if err := c.Update(ctx, current); err != nil {
+		if errors.IsInvalid(err) || errors.IsForbidden(err) || errors.IsBadRequest(err) {
+			return resource, conflicts, false, &restoreFailure{fmt.Sprintf("failed to restore %s: %v", resource, err)}
+		}
+		return resource, conflicts, false, fmt.Errorf("failed to restore %s: %w", resource, err)
+	}
+	return resource, conflicts, true, nil
+}
+
+// restoreConflicts returns the fields of current that differ from the backup's original
+// state outside the modified paths, i.e. were changed by others
+func restoreConflicts(
+	backup *remediationv1alpha1.RemediationBackup,
+	original *unstructured.Unstructured,
+	current *unstructured.Unstructured,
+	modifiedPaths []string,
+) []string {
+	var conflicts []string
+	for _, path := range changedFields(backup, original, current) {
+		if !pathModified(path, modifiedPaths) {
+			conflicts = append(conflicts, path)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// changedFields returns the paths of the spec fields, labels and annotations of current
+// that differ from the backup's original state
+func changedFields(
+	backup *remediationv1alpha1.RemediationBackup,
+	original *unstructured.Unstructured,
+	current *unstructured.Unstructured,
+) []string {
+	var changed []string
+	diffFields("spec", original.Object["spec"], current.Object["spec"], &changed)
+	diffMetadata("metadata.labels", backup.Spec.OriginalLabels, current.GetLabels(), nil, &changed)
+	diffMetadata("metadata.annotations", backup.Spec.OriginalAnnotations, current.GetAnnotations(),
+		systemAnnotationPrefixes, &changed)
+	return changed
+}
+
+// diffFields appends the paths at which two decoded JSON values differ
+func diffFields(path string, original, current interface{}, changed *[]string) {
+	originalMap, originalIsMap := original.(map[string]interface{})
+	currentMap, currentIsMap := current.(map[string]interface{})
+	if originalIsMap && currentIsMap {
+		keys := make(map[string]bool, len(originalMap)+len(currentMap))
+		for key := range originalMap {
+			keys[key] = true
+		}
+		for key := range currentMap {
+			keys[key] = true
+		}
+		for key := range keys {
+			diffFields(path+"."+key, originalMap[key], currentMap[key], changed)
+		}
+		return
+	}
+
+	originalList, originalIsList := original.([]interface{})
+	currentList, currentIsList := current.([]interface{})
+	if originalIsList && currentIsList && len(originalList) == len(currentList) {
+		for i := range originalList {
+			diffFields(fmt.Sprintf("%s[%d]", path, i), originalList[i], currentList[i], changed)
+		}
+		return
+	}
+
+	if !equality.Semantic.DeepEqual(original, current) {
+		*changed = append(*changed, path)
+	}
+}
+
+// diffMetadata appends the label or annotation keys that differ, ignoring keys managed by
+// KubeMedic or starting with one of the ignored prefixes
+func diffMetadata(path string, original, current map[string]string, ignored []string, changed *[]string) {
+	keys := make(map[string]bool, len(original)+len(current))
+	for key := range original {
+		keys[key] = true
+	}
+	for key := range current {
+		keys[key] = true
+	}
+	for key := range keys {
+		if strings.HasPrefix(key, kubemedicKeyPrefix) || hasAnyPrefix(key, ignored) {
+			continue
+		}
+		originalValue, inOriginal := original[key]
+		currentValue, inCurrent := current[key]
+		if inOriginal != inCurrent || originalValue != currentValue {
+			*changed = append(*changed, path+"."+key)
+		}
+	}
+}
+
+// pathModified reports whether the field path lies within one of the modified paths,
+// which may use [*] for any list index
+func pathModified(path string, modified []string) bool {
+	path = listIndex.ReplaceAllString(path, "[*]")
+	for _, prefix := range modified {
+		if path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyPrefix reports whether s starts with any of the prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyOriginalState sets the spec, labels and annotations of current to those saved in
+// the backup. Annotations owned by other controllers keep their current values.
+func applyOriginalState(
+	backup *remediationv1alpha1.RemediationBackup,
+	original *unstructured.Unstructured,
+	current *unstructured.Unstructured,
+) {
+	if spec, ok := original.Object["spec"]; ok {
+		current.Object["spec"] = runtime.DeepCopyJSONValue(spec)
+	} else {
+		delete(current.Object, "spec")
+	}
+
+	labels := make(map[string]string, len(backup.Spec.OriginalLabels))
+	for key, value := range backup.Spec.OriginalLabels {
+		labels[key] = value
+	}
+	current.SetLabels(labels)
+
+	annotations := make(map[string]string, len(backup.Spec.OriginalAnnotations))
+	for key, value := range backup.Spec.OriginalAnnotations {
+		if !hasAnyPrefix(key, systemAnnotationPrefixes) {
+			annotations[key] = value
+		}
+	}
+	for key, value := range current.GetAnnotations() {
+		if hasAnyPrefix(key, systemAnnotationPrefixes) {
+			annotations[key] = value
+		}
+	}
+	current.SetAnnotations(annotations)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RemediationRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&remediationv1alpha1.RemediationRestore{}).
+		Complete(r)
+}
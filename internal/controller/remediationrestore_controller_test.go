@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"slices"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+func TestCheckRestoreAllowed(t *testing.T) {
+	policy := &remediationv1alpha1.SelfRemediationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "scale", Namespace: "shop", UID: "policy-uid"},
+	}
+	otherPolicy := &remediationv1alpha1.SelfRemediationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "scale", Namespace: "shop", UID: "other-uid"},
+	}
+
+	tests := []struct {
+		name       string
+		owner      *remediationv1alpha1.SelfRemediationPolicy
+		policy     *remediationv1alpha1.SelfRemediationPolicy
+		namespace  string
+		labels     map[string]string
+		tamper     bool
+		wantDenied bool
+	}{
+		{name: "backup taken by the policy", owner: policy, policy: policy, namespace: "shop"},
+		{name: "backup without an owner", policy: policy, namespace: "shop", wantDenied: true},
+		{name: "owner recreated", owner: otherPolicy, policy: policy, namespace: "shop", wantDenied: true},
+		{name: "owner deleted", owner: policy, namespace: "shop", wantDenied: true},
+		{name: "content changed", owner: policy, policy: policy, namespace: "shop", tamper: true, wantDenied: true},
+		{name: "denied namespace", owner: policy, policy: policy, namespace: "kube-system", wantDenied: true},
+		{
+			name: "protected target", owner: policy, policy: policy, namespace: "shop",
+			labels: map[string]string{"kubemedic.io/protected": "true"}, wantDenied: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: tt.namespace, Labels: tt.labels},
+			}
+			objects := []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+				deployment.DeepCopy(),
+			}
+			if tt.policy != nil {
+				objects = append(objects, tt.policy.DeepCopy())
+			}
+			r := &RemediationRestoreReconciler{
+				Client:   newTestClient(t, objects...),
+				Scheme:   testScheme(t),
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			backup := testBackup(t, policy, deployment)
+			if tt.owner != nil {
+				backup.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(tt.owner,
+					remediationv1alpha1.GroupVersion.WithKind("SelfRemediationPolicy"))}
+			}
+			if tt.tamper {
+				backup.Spec.OriginalState.Raw = append(backup.Spec.OriginalState.Raw[:len(backup.Spec.OriginalState.Raw)-1], ' ', '}')
+			}
+
+			err := r.checkRestoreAllowed(context.Background(), backup)
+			var failure *restoreFailure
+			denied := goerrors.As(err, &failure)
+			if err != nil && !denied {
+				t.Fatalf("checkRestoreAllowed returned error: %v", err)
+			}
+			if denied != tt.wantDenied {
+				t.Errorf("checkRestoreAllowed denied = %v (%v), want %v", denied, err, tt.wantDenied)
+			}
+		})
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	decode := func(raw string) interface{} {
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	tests := []struct {
+		name     string
+		original string
+		current  string
+		want     []string
+	}{
+		{name: "equal", original: `{"replicas": 3}`, current: `{"replicas": 3}`},
+		{name: "changed value", original: `{"replicas": 3}`, current: `{"replicas": 5}`, want: []string{"spec.replicas"}},
+		{name: "added field", original: `{}`, current: `{"paused": true}`, want: []string{"spec.paused"}},
+		{name: "removed field", original: `{"paused": true}`, current: `{}`, want: []string{"spec.paused"}},
+		{
+			name:     "nested fields",
+			original: `{"replicas": 3, "strategy": {"type": "RollingUpdate", "rollingUpdate": {"maxSurge": 1}}}`,
+			current:  `{"replicas": 4, "strategy": {"type": "RollingUpdate", "rollingUpdate": {"maxSurge": 2}}}`,
+			want:     []string{"spec.replicas", "spec.strategy.rollingUpdate.maxSurge"},
+		},
+		{
+			name:     "list items",
+			original: `{"containers": [{"name": "app", "image": "app:1"}, {"name": "log", "image": "log:1"}]}`,
+			current:  `{"containers": [{"name": "app", "image": "app:1"}, {"name": "log", "image": "log:2"}]}`,
+			want:     []string{"spec.containers[1].image"},
+		},
+		{
+			name:     "list of another length",
+			original: `{"containers": [{"name": "app"}]}`,
+			current:  `{"containers": [{"name": "app"}, {"name": "log"}]}`,
+			want:     []string{"spec.containers"},
+		},
+		{name: "map replaced by a value", original: `{"selector": {"app": "shop"}}`, current: `{"selector": null}`, want: []string{"spec.selector"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changed []string
+			diffFields("spec", decode(tt.original), decode(tt.current), &changed)
+			sort.Strings(changed)
+			if !slices.Equal(changed, tt.want) {
+				t.Errorf("diffFields() = %v, want %v", changed, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathModified(t *testing.T) {
+	tests := []struct {
+		path     string
+		modified []string
+		want     bool
+	}{
+		{path: "spec.replicas", modified: []string{replicasPath}, want: true},
+		{path: "spec.replicasHistory", modified: []string{replicasPath}},
+		{path: "spec.template.spec.containers[0].resources.limits.cpu", modified: []string{resourcesPath}, want: true},
+		{path: "spec.template.spec.containers[12].resources", modified: []string{resourcesPath}, want: true},
+		{path: "spec.template.spec.containers[0].image", modified: []string{resourcesPath}},
+		{path: "spec.template.spec.containers[0].image", modified: []string{templatePath}, want: true},
+		{path: "spec.template", modified: []string{templateAnnotationsPath}},
+		{path: "spec.template.metadata.annotations.restartedAt", modified: []string{replicasPath, templateAnnotationsPath}, want: true},
+		{path: "spec.maxReplicas", modified: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := pathModified(tt.path, tt.modified); got != tt.want {
+				t.Errorf("pathModified(%q, %v) = %v, want %v", tt.path, tt.modified, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+const (
+	// resizeModeAnnotation records whether resources were changed in place or through the pod template
+	resizeModeAnnotation = "kubemedic.io/resize-mode"
+
+	resizeInPlace  = "InPlace"
+	resizeTemplate = "Template"
+)
+
+// resourcesTarget returns the Deployment or StatefulSet an UpdateResources action acts on.
+// Actions without a target update the workload owning the pod from the policy's TargetRef.
+func (r *SelfRemediationPolicyReconciler) resourcesTarget(
+	ctx context.Context,
+	action remediationv1alpha1.Action,
+	pod *corev1.Pod,
+) (client.Object, error) {
+	switch action.Target.Kind {
+	case "Deployment", "StatefulSet":
+		return r.getActionTarget(ctx, action)
+	case "":
+		if pod == nil {
+			return nil, fmt.Errorf("no workload to update")
+		}
+		return r.podWorkload(ctx, pod)
+	default:
+		return nil, fmt.Errorf("unsupported target kind %q for action %s", action.Target.Kind, action.Type)
+	}
+}
+
+// raisedResources applies the adjustments to a container's resources. It returns the new
+// resources and whether anything changed; requests and limits are never lowered.
+func raisedResources(
+	current corev1.ResourceRequirements,
+	adjustments []remediationv1alpha1.ResourceAdjustment,
+) (corev1.ResourceRequirements, bool, error) {
+	updated := *current.DeepCopy()
+	for _, adjustment := range adjustments {
+		name := corev1.ResourceName(adjustment.Resource)
+		if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+			return updated, false, fmt.Errorf("unsupported resource %q: must be cpu or memory", adjustment.Resource)
+		}
+
+		request, err := adjustedQuantity(updated.Requests, name, adjustment.Request, adjustment)
+		if err != nil {
+			return updated, false, fmt.Errorf("invalid %s request: %w", name, err)
+		}
+		limit, err := adjustedQuantity(updated.Limits, name, adjustment.Limit, adjustment)
+		if err != nil {
+			return updated, false, fmt.Errorf("invalid %s limit: %w", name, err)
+		}
+		// A request above the limit would be rejected by the API server
+		if request != nil && limit != nil && request.Cmp(*limit) > 0 {
+			limit = request
+		}
+
+		if request != nil {
+			if updated.Requests == nil {
+				updated.Requests = corev1.ResourceList{}
+			}
+			updated.Requests[name] = *request
+		}
+		if limit != nil {
+			if updated.Limits == nil {
+				updated.Limits = corev1.ResourceList{}
+			}
+			updated.Limits[name] = *limit
+		}
+	}
+	return updated, !equality.Semantic.DeepEqual(current, updated), nil
+}
+
+// adjustedQuantity computes the new value of one request or limit. An absolute value takes
+// precedence over the multiplier, the result is capped at Max, and the current value is
+// kept when the result would lower it. It returns nil when the value stays unset.
+func adjustedQuantity(
+	list corev1.ResourceList,
+	name corev1.ResourceName,
+	absolute string,
+	adjustment remediationv1alpha1.ResourceAdjustment,
+) (*resource.Quantity, error) {
+	current, hasCurrent := list[name]
+
+	var next *resource.Quantity
+	switch {
+	case absolute != "":
+		quantity, err := resource.ParseQuantity(absolute)
+		if err != nil {
+			return nil, err
+		}
+		next = &quantity
+	case adjustment.Multiplier != "" && hasCurrent:
+		multiplier, err := strconv.ParseFloat(adjustment.Multiplier, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid multiplier %q: %w", adjustment.Multiplier, err)
+		}
+		if name == corev1.ResourceCPU {
+			next = resource.NewMilliQuantity(int64(math.Ceil(float64(current.MilliValue())*multiplier)), current.Format)
+		} else {
+			next = resource.NewQuantity(int64(math.Ceil(float64(current.Value())*multiplier)), current.Format)
+		}
+	default:
+		if !hasCurrent {
+			return nil, nil
+		}
+		return &current, nil
+	}
+
+	if adjustment.Max != "" {
+		max, err := resource.ParseQuantity(adjustment.Max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max %q: %w", adjustment.Max, err)
+		}
+		if next.Cmp(max) > 0 {
+			next = &max
+		}
+	}
+	if hasCurrent && next.Cmp(current) < 0 {
+		return &current, nil
+	}
+	return next, nil
+}
+
+// updateResources raises the resources of the selected containers of a Deployment or
+// StatefulSet. The running pods are resized in place when the cluster supports it;
+// otherwise the pod template is changed, which rolls the workload. The original resources
+// are saved on the workload so the change can be reverted after the configured duration.
+func (r *SelfRemediationPolicyReconciler) updateResources(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	rule string,
+	action remediationv1alpha1.Action,
+	target client.Object,
+) (bool, error) {
+	log := log.FromContext(ctx).WithValues("target", client.ObjectKeyFromObject(target).String())
+	params := action.ResourceParams
+	if params == nil || len(params.Adjustments) == 0 {
+		log.Info("Skipping action: resource parameters not configured")
+		return false, nil
+	}
+	workload, ok := asScalableWorkload(target)
+	if !ok {
+		log.Info("Skipping action: UpdateResources requires a Deployment or StatefulSet target")
+		return false, nil
+	}
+	var revertAfter time.Duration
+	if params.Duration != "" {
+		var err error
+		if revertAfter, err = time.ParseDuration(params.Duration); err != nil {
+			log.Error(err, "Skipping action: invalid duration")
+			return false, nil
+		}
+	}
+
+	selected := make(map[string]bool, len(params.Containers))
+	for _, name := range params.Containers {
+		selected[name] = true
+	}
+	desired := make(map[string]corev1.ResourceRequirements)
+	for _, container := range workload.template.Spec.Containers {
+		if len(selected) > 0 && !selected[container.Name] {
+			continue
+		}
+		updated, changed, err := raisedResources(container.Resources, params.Adjustments)
+		if err != nil {
+			log.Error(err, "Skipping action: invalid resource adjustment")
+			return false, nil
+		}
+		if changed {
+			desired[container.Name] = updated
+		}
+	}
+	if len(desired) == 0 {
+		log.Info("Skipping action: resources are already at or above the requested values")
+		return false, nil
+	}
+
+	// Take the backup before pods are resized or the template changes
+	if _, err := r.snapshot(ctx, policy, action.Type, workload.Object, revertAfter, resourcesPath); err != nil {
+		return false, err
+	}
+
+	mode := resizeInPlace
+	resized, err := r.resizePods(ctx, workload, desired)
+	if err != nil {
+		return false, err
+	}
+	if !resized {
+		mode = resizeTemplate
+		applyContainerResources(workload.template, desired)
+	}
+
+	now := time.Now()
+	markRemediated(workload, now)
+	if revertAfter > 0 {
+		annotations := workload.GetAnnotations()
+		// A template change is not reverted in place
+		if annotations[resizeModeAnnotation] != resizeTemplate {
+			annotations[resizeModeAnnotation] = mode
+		}
+		workload.SetAnnotations(annotations)
+		scheduleReversion(workload, policy, rule, nil, revertAfter, now)
+	}
+	if err := r.Update(ctx, workload.Object); err != nil {
+		return false, fmt.Errorf("failed to update resources: %w", err)
+	}
+	if revertAfter > 0 {
+		trackPendingReversion(policy, targetKind(workload.Object), workload, now.Add(revertAfter))
+	}
+
+	log.Info("Updated container resources", "mode", mode, "containers", len(desired), "duration", params.Duration)
+	r.Recorder.Eventf(policy, corev1.EventTypeNormal, "ResourcesUpdated",
+		"Updated resources of %d containers of %s %s/%s (%s)",
+		len(desired), targetKind(workload.Object), workload.GetNamespace(), workload.GetName(), mode)
+	return true, nil
+}
+
+// applyContainerResources sets the resources of the named containers in the pod template
+func applyContainerResources(template *corev1.PodTemplateSpec, resources map[string]corev1.ResourceRequirements) {
+	for i := range template.Spec.Containers {
+		if updated, ok := resources[template.Spec.Containers[i].Name]; ok {
+			template.Spec.Containers[i].Resources = updated
+		}
+	}
+}
+
+// resizePods resizes the containers of every running pod of the workload in place through
+// the pod resize subresource. It returns false, without an error, when the cluster does not
+// support in-place resize so the caller can fall back to a template change. When a pod
+// cannot be resized, the pods resized before it are resized back, so that the pods never
+// run with different resources.
+func (r *SelfRemediationPolicyReconciler) resizePods(
+	ctx context.Context,
+	workload *scalableWorkload,
+	resources map[string]corev1.ResourceRequirements,
+) (bool, error) {
+	if workload.selector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(workload.selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid selector on %s %s/%s: %w",
+			targetKind(workload.Object), workload.GetNamespace(), workload.GetName(), err)
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(workload.GetNamespace()),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return false, fmt.Errorf("failed to list pods: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return false, nil
+	}
+
+	var done []*corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		resized := pod.DeepCopy()
+		changed := false
+		for j := range resized.Spec.Containers {
+			container := &resized.Spec.Containers[j]
+			if updated, ok := resources[container.Name]; ok && !equality.Semantic.DeepEqual(container.Resources, updated) {
+				container.Resources = updated
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		if err := r.SubResource("resize").Update(ctx, resized); err != nil {
+			if errors.IsNotFound(err) && r.podDeleted(ctx, pod) {
+				// The pod was deleted since it was listed, so there is nothing to resize
+				continue
+			}
+			r.undoResize(ctx, done)
+			if resizeUnsupported(err) {
+				log.FromContext(ctx).V(1).Info("In-place resize not available", "pod", pod.Name, "error", err.Error())
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to resize pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+		done = append(done, pod)
+	}
+	return true, nil
+}
+
+// undoResize resizes pods back to the container resources they had before, as given
+func (r *SelfRemediationPolicyReconciler) undoResize(ctx context.Context, originals []*corev1.Pod) {
+	log := log.FromContext(ctx)
+	for _, original := range originals {
+		resources := make(map[string]corev1.ResourceRequirements, len(original.Spec.Containers))
+		for _, container := range original.Spec.Containers {
+			resources[container.Name] = container.Resources
+		}
+		var pod corev1.Pod
+		if err := r.Get(ctx, client.ObjectKeyFromObject(original), &pod); err != nil {
+			log.Error(err, "Failed to get pod to undo its resize", "pod", original.Name)
+			continue
+		}
+		for i := range pod.Spec.Containers {
+			if previous, ok := resources[pod.Spec.Containers[i].Name]; ok {
+				pod.Spec.Containers[i].Resources = previous
+			}
+		}
+		if err := r.SubResource("resize").Update(ctx, &pod); err != nil {
+			log.Error(err, "Failed to undo resize of pod", "pod", pod.Name)
+		}
+	}
+}
+
+// podDeleted reports whether the pod no longer exists, or was replaced by a pod of the same
+// name, so a NotFound from its resize refers to the pod rather than the resize subresource
+func (r *SelfRemediationPolicyReconciler) podDeleted(ctx context.Context, pod *corev1.Pod) bool {
+	var current corev1.Pod
+	if err := r.Get(ctx, client.ObjectKeyFromObject(pod), &current); err != nil {
+		return errors.IsNotFound(err)
+	}
+	return current.UID != pod.UID
+}
+
+// resizeUnsupported reports whether a resize error means the cluster does not serve the
+// pod resize subresource, as opposed to a rejected change or a transient failure. A NotFound
+// only means so for a pod that still exists, see podDeleted.
+func resizeUnsupported(err error) bool {
+	return errors.IsNotFound(err) || errors.IsMethodNotSupported(err)
+}
+
+// revertResources restores the container resources from the workload's original state,
+// resizing the running pods back when the change was made in place, or restarting them when
+// they cannot be resized back. The caller persists the workload.
+func (r *SelfRemediationPolicyReconciler) revertResources(
+	ctx context.Context,
+	target client.Object,
+	original client.Object,
+) error {
+	workload, ok := asScalableWorkload(target)
+	if !ok {
+		return nil
+	}
+	originalWorkload, ok := asScalableWorkload(original)
+	if !ok {
+		log.FromContext(ctx).Info("Original resources unknown, leaving them in place",
+			"target", client.ObjectKeyFromObject(target).String())
+		return nil
+	}
+
+	originals := make(map[string]corev1.ResourceRequirements)
+	for _, container := range originalWorkload.template.Spec.Containers {
+		originals[container.Name] = container.Resources
+	}
+
+	if target.GetAnnotations()[resizeModeAnnotation] != resizeInPlace {
+		applyContainerResources(workload.template, originals)
+		return nil
+	}
+
+	// Pods created since the change already run the unchanged template
+	resized, err := r.resizePods(ctx, workload, originals)
+	if err != nil && !errors.IsInvalid(err) && !errors.IsForbidden(err) {
+		return err
+	}
+	if !resized {
+		// The pods cannot be resized back, for example when the cluster does not allow
+		// lowering memory limits in place, so they are replaced from the unchanged template
+		reason := "in-place resize is not available"
+		if err != nil {
+			reason = err.Error()
+		}
+		log.FromContext(ctx).Info("Pods cannot be resized back in place, restarting them",
+			"target", client.ObjectKeyFromObject(target).String(), "reason", reason)
+		applyContainerResources(workload.template, originals)
+		stampRestart(workload.template, time.Now())
+	}
+	return nil
+}
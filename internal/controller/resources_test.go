@@ -0,0 +1,262 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+func TestAdjustedQuantity(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    string
+		resource   corev1.ResourceName
+		absolute   string
+		adjustment remediationv1alpha1.ResourceAdjustment
+		want       string
+		wantErr    bool
+	}{
+		{name: "unset stays unset", resource: corev1.ResourceCPU, adjustment: remediationv1alpha1.ResourceAdjustment{Multiplier: "2"}},
+		{name: "unchanged", current: "500m", resource: corev1.ResourceCPU, want: "500m"},
+		{name: "absolute", current: "500m", resource: corev1.ResourceCPU, absolute: "1", want: "1"},
+		{name: "absolute sets an unset value", resource: corev1.ResourceMemory, absolute: "1Gi", want: "1Gi"},
+		{
+			name:       "absolute takes precedence over the multiplier",
+			current:    "500m",
+			resource:   corev1.ResourceCPU,
+			absolute:   "750m",
+			adjustment: remediationv1alpha1.ResourceAdjustment{Multiplier: "4"},
+			want:       "750m",
+		},
+		{name: "CPU multiplier", current: "500m", resource: corev1.ResourceCPU, adjustment: remediationv1alpha1.ResourceAdjustment{Multiplier: "1.5"}, want: "750m"},
+		{name: "CPU multiplier rounds up to a millicore", current: "3m", resource: corev1.ResourceCPU, adjustment: remediationv1alpha1.ResourceAdjustment{Multiplier: "1.5"}, want: "5m"},
+		{name: "memory multiplier", current: "512Mi", resource: corev1.ResourceMemory, adjustment: remediationv1alpha1.ResourceAdjustment{Multiplier: "2"}, want: "1Gi"},
+		{name: "capped at max", current: "512Mi", resource: corev1.ResourceMemory, adjustment: remediationv1alpha1.ResourceAdjustment{Multiplier: "4", Max: "1Gi"}, want: "1Gi"},
+		{name: "never lowered", current: "1", resource: corev1.ResourceCPU, absolute: "500m", want: "1"},
+		{name: "never lowered by max", current: "2Gi", resource: corev1.ResourceMemory, adjustment: remediationv1alpha1.ResourceAdjustment{Multiplier: "2", Max: "1Gi"}, want: "2Gi"},
+		{name: "invalid absolute", current: "500m", resource: corev1.ResourceCPU, absolute: "lots", wantErr: true},
+		{name: "invalid multiplier", current: "500m", resource: corev1.ResourceCPU, adjustment: remediationv1alpha1.ResourceAdjustment{Multiplier: "double"}, wantErr: true},
+		{name: "invalid max", current: "500m", resource: corev1.ResourceCPU, adjustment: remediationv1alpha1.ResourceAdjustment{Multiplier: "2", Max: "lots"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := corev1.ResourceList{}
+			if tt.current != "" {
+				list[tt.resource] = resource.MustParse(tt.current)
+			}
+			got, err := adjustedQuantity(list, tt.resource, tt.absolute, tt.adjustment)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("adjustedQuantity() error = %v, want error %v", err, tt.wantErr)
+			}
+			switch {
+			case tt.wantErr:
+			case tt.want == "":
+				if got != nil {
+					t.Errorf("adjustedQuantity() = %s, want unset", got)
+				}
+			case got == nil || got.Cmp(resource.MustParse(tt.want)) != 0:
+				t.Errorf("adjustedQuantity() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRaisedResources(t *testing.T) {
+	requirements := func(requests, limits corev1.ResourceList) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: requests, Limits: limits}
+	}
+	cpu := func(value string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(value)}
+	}
+
+	tests := []struct {
+		name        string
+		current     corev1.ResourceRequirements
+		adjustments []remediationv1alpha1.ResourceAdjustment
+		want        corev1.ResourceRequirements
+		wantChanged bool
+		wantErr     bool
+	}{
+		{
+			name:        "request and limit multiplied",
+			current:     requirements(cpu("500m"), cpu("1")),
+			adjustments: []remediationv1alpha1.ResourceAdjustment{{Resource: "cpu", Multiplier: "2"}},
+			want:        requirements(cpu("1"), cpu("2")),
+			wantChanged: true,
+		},
+		{
+			name:        "limit raised to the request",
+			current:     requirements(cpu("500m"), cpu("1")),
+			adjustments: []remediationv1alpha1.ResourceAdjustment{{Resource: "cpu", Request: "1500m"}},
+			want:        requirements(cpu("1500m"), cpu("1500m")),
+			wantChanged: true,
+		},
+		{
+			name:        "unset limit stays unset",
+			current:     requirements(cpu("500m"), nil),
+			adjustments: []remediationv1alpha1.ResourceAdjustment{{Resource: "cpu", Multiplier: "2"}},
+			want:        requirements(cpu("1"), nil),
+			wantChanged: true,
+		},
+		{
+			name:        "already at max",
+			current:     requirements(cpu("1"), nil),
+			adjustments: []remediationv1alpha1.ResourceAdjustment{{Resource: "cpu", Multiplier: "2", Max: "1"}},
+			want:        requirements(cpu("1"), nil),
+		},
+		{
+			name:        "unsupported resource",
+			current:     requirements(cpu("500m"), nil),
+			adjustments: []remediationv1alpha1.ResourceAdjustment{{Resource: "ephemeral-storage", Multiplier: "2"}},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed, err := raisedResources(tt.current, tt.adjustments)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("raisedResources() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if changed != tt.wantChanged || !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("raisedResources() = %+v, %v, want %+v, %v", got, changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestResizePods(t *testing.T) {
+	podResource := schema.GroupResource{Resource: "pods"}
+	tests := []struct {
+		name string
+		// resizeErr returns the error resizing the pod fails with, if any; the client is
+		// given so the pod can be deleted first
+		resizeErr   func(ctx context.Context, c client.Client, pod client.Object) error
+		wantResized bool
+		wantErr     bool
+		wantPods    []string
+	}{
+		{
+			name:        "resized",
+			resizeErr:   func(context.Context, client.Client, client.Object) error { return nil },
+			wantResized: true,
+			wantPods:    []string{"app-a", "app-b"},
+		},
+		{
+			name: "pod deleted after listing",
+			resizeErr: func(ctx context.Context, c client.Client, pod client.Object) error {
+				if pod.GetName() != "app-a" {
+					return nil
+				}
+				if err := c.Delete(ctx, pod); err != nil {
+					return err
+				}
+				return errors.NewNotFound(podResource, pod.GetName())
+			},
+			wantResized: true,
+			wantPods:    []string{"app-b"},
+		},
+		{
+			name: "resize subresource not found",
+			resizeErr: func(_ context.Context, _ client.Client, pod client.Object) error {
+				return errors.NewNotFound(podResource, pod.GetName())
+			},
+		},
+		{
+			name: "resize method not supported",
+			resizeErr: func(context.Context, client.Client, client.Object) error {
+				return errors.NewMethodNotSupported(podResource, "update")
+			},
+		},
+		{
+			name: "resize rejected",
+			resizeErr: func(_ context.Context, _ client.Client, pod client.Object) error {
+				return errors.NewInvalid(corev1.SchemeGroupVersion.WithKind("Pod").GroupKind(), pod.GetName(), nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]string{"app": "shop"}
+			pod := func(name string) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", UID: types.UID("uid-" + name), Labels: labels},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+					Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+				}
+			}
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "shop"},
+				Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+			}
+
+			var resized []string
+			c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+				WithObjects(pod("app-a"), pod("app-b")).
+				WithInterceptorFuncs(interceptor.Funcs{
+					SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+						if subResource != "resize" {
+							t.Fatalf("unexpected update of subresource %s", subResource)
+						}
+						if err := tt.resizeErr(ctx, c, obj); err != nil {
+							return err
+						}
+						resized = append(resized, obj.GetName())
+						return nil
+					},
+				}).Build()
+			r := &SelfRemediationPolicyReconciler{Client: c}
+
+			workload, _ := asScalableWorkload(deployment)
+			requests := map[string]corev1.ResourceRequirements{"app": {
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			}}
+			got, err := r.resizePods(context.Background(), workload, requests)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("resizePods() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.wantResized {
+				t.Errorf("resizePods() = %v, want %v", got, tt.wantResized)
+			}
+			if tt.wantResized && !slices.Equal(resized, tt.wantPods) {
+				t.Errorf("resized pods %v, want %v", resized, tt.wantPods)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// restartedAtAnnotation is the pod template annotation `kubectl rollout restart` sets
+// to roll a workload
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+const defaultMaxConcurrentRestarts = int32(1)
+
+// restartStrategy returns the configured restart strategy, defaulting to Evict
+func restartStrategy(params *remediationv1alpha1.RestartParameters) string {
+	if params == nil || params.Strategy == "" {
+		return remediationv1alpha1.RestartEvict
+	}
+	return params.Strategy
+}
+
+// maxConcurrentRestarts returns how many pods of a workload may be restarting at once
+func maxConcurrentRestarts(params *remediationv1alpha1.RestartParameters) int32 {
+	if params == nil || params.MaxConcurrentRestarts == nil || *params.MaxConcurrentRestarts < 1 {
+		return defaultMaxConcurrentRestarts
+	}
+	return *params.MaxConcurrentRestarts
+}
+
+// resolveRestartTarget returns the object a RestartPod action acts on: the pod to evict
+// for the Evict strategy, or the owning Deployment or StatefulSet for RollingRestart.
+// Actions without a target restart the pod from the policy's TargetRef.
+func (r *SelfRemediationPolicyReconciler) resolveRestartTarget(
+	ctx context.Context,
+	action remediationv1alpha1.Action,
+	pod *corev1.Pod,
+) (client.Object, error) {
+	strategy := restartStrategy(action.RestartParams)
+
+	switch action.Target.Kind {
+	case "", "Pod":
+		if action.Target.Name != "" {
+			namespace := action.Target.Namespace
+			if namespace == "" && pod != nil {
+				namespace = pod.Namespace
+			}
+			pod = &corev1.Pod{}
+			if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: action.Target.Name}, pod); err != nil {
+				return nil, fmt.Errorf("failed to get Pod: %w", err)
+			}
+		}
+	case "Deployment", "StatefulSet":
+		if strategy != remediationv1alpha1.RestartRollingRestart {
+			return nil, fmt.Errorf("restart strategy %s requires a Pod target, got %s", strategy, action.Target.Kind)
+		}
+		return r.getActionTarget(ctx, action)
+	default:
+		return nil, fmt.Errorf("unsupported target kind %q for action %s", action.Target.Kind, action.Type)
+	}
+
+	if pod == nil {
+		return nil, fmt.Errorf("no pod to restart")
+	}
+	if strategy == remediationv1alpha1.RestartRollingRestart {
+		return r.podWorkload(ctx, pod)
+	}
+	return pod, nil
+}
+
+// podWorkload returns the Deployment or StatefulSet that controls the pod
+func (r *SelfRemediationPolicyReconciler) podWorkload(ctx context.Context, pod *corev1.Pod) (client.Object, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil, fmt.Errorf("pod %s/%s has no controlling workload", pod.Namespace, pod.Name)
+	}
+
+	switch owner.Kind {
+	case "ReplicaSet":
+		var replicaSet appsv1.ReplicaSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: owner.Name}, &replicaSet); err != nil {
+			return nil, fmt.Errorf("failed to get ReplicaSet: %w", err)
+		}
+		rsOwner := metav1.GetControllerOf(&replicaSet)
+		if rsOwner == nil || rsOwner.Kind != "Deployment" {
+			return nil, fmt.Errorf("ReplicaSet %s/%s is not controlled by a Deployment", replicaSet.Namespace, replicaSet.Name)
+		}
+		var deployment appsv1.Deployment
+		if err := r.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: rsOwner.Name}, &deployment); err != nil {
+			return nil, fmt.Errorf("failed to get Deployment: %w", err)
+		}
+		return &deployment, nil
+
+	case "StatefulSet":
+		var statefulSet appsv1.StatefulSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: owner.Name}, &statefulSet); err != nil {
+			return nil, fmt.Errorf("failed to get StatefulSet: %w", err)
+		}
+		return &statefulSet, nil
+
+	default:
+		return nil, fmt.Errorf("pod %s/%s is controlled by unsupported kind %s", pod.Namespace, pod.Name, owner.Kind)
+	}
+}
+
+// restartPods executes a RestartPod action against a resolved restart target and
+// reports whether a restart was started
+func (r *SelfRemediationPolicyReconciler) restartPods(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	action remediationv1alpha1.Action,
+	target client.Object,
+) (bool, error) {
+	switch target := target.(type) {
+	case *corev1.Pod:
+		return r.evictPod(ctx, policy, action, target)
+	case *appsv1.Deployment, *appsv1.StatefulSet:
+		return r.rollingRestart(ctx, policy, action, target)
+	default:
+		return false, fmt.Errorf("cannot restart %T %s", target, client.ObjectKeyFromObject(target))
+	}
+}
+
+// evictPod evicts the pod through the Eviction API so PodDisruptionBudgets are honoured.
+// The eviction is skipped while too many of the pod's siblings are already restarting.
+func (r *SelfRemediationPolicyReconciler) evictPod(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	action remediationv1alpha1.Action,
+	pod *corev1.Pod,
+) (bool, error) {
+	log := log.FromContext(ctx).WithValues("pod", client.ObjectKeyFromObject(pod).String())
+
+	if pod.DeletionTimestamp != nil {
+		log.Info("Skipping action: pod is already terminating")
+		return false, nil
+	}
+
+	restarting, err := r.restartingSiblings(ctx, pod)
+	if err != nil {
+		return false, err
+	}
+	if limit := maxConcurrentRestarts(action.RestartParams); restarting >= limit {
+		log.Info("Skipping action: too many pods restarting", "restarting", restarting, "max_concurrent_restarts", limit)
+		r.Recorder.Eventf(policy, corev1.EventTypeNormal, "RestartLimited",
+			"Eviction of Pod %s/%s skipped: %d sibling pods are already restarting (max %d)",
+			pod.Namespace, pod.Name, restarting, limit)
+		return false, nil
+	}
+
+	if _, err := r.snapshot(ctx, policy, action.Type, pod, 0); err != nil {
+		return false, err
+	}
+
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+	}
+	if err := r.SubResource("eviction").Create(ctx, pod, eviction); err != nil {
+		switch {
+		case errors.IsNotFound(err):
+			log.Info("Skipping action: pod no longer exists")
+			return false, nil
+		case errors.IsTooManyRequests(err):
+			log.Info("Eviction blocked by a PodDisruptionBudget")
+			r.Recorder.Eventf(policy, corev1.EventTypeWarning, "EvictionBlocked",
+				"Eviction of Pod %s/%s blocked by a PodDisruptionBudget", pod.Namespace, pod.Name)
+			return false, nil
+		default:
+			return false, fmt.Errorf("failed to evict pod: %w", err)
+		}
+	}
+
+	log.Info("Evicted pod")
+	r.Recorder.Eventf(policy, corev1.EventTypeNormal, "PodEvicted",
+		"Evicted Pod %s/%s", pod.Namespace, pod.Name)
+	return true, nil
+}
+
+// restartingSiblings counts the other pods of the pod's controller that are terminating
+// or not ready
+func (r *SelfRemediationPolicyReconciler) restartingSiblings(ctx context.Context, pod *corev1.Pod) (int32, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return 0, nil
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(pod.Namespace)); err != nil {
+		return 0, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var restarting int32
+	for i := range pods.Items {
+		sibling := &pods.Items[i]
+		if sibling.UID == pod.UID {
+			continue
+		}
+		if siblingOwner := metav1.GetControllerOf(sibling); siblingOwner == nil || siblingOwner.UID != owner.UID {
+			continue
+		}
+		if sibling.DeletionTimestamp != nil || !podReady(sibling) {
+			restarting++
+		}
+	}
+	return restarting, nil
+}
+
+// podReady reports whether the pod's Ready condition is true
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// rollingRestart rolls every pod of the workload by stamping the pod template, the same
+// way `kubectl rollout restart` does. It is skipped while a rollout is still replacing
+// more pods than the concurrency limit allows.
+func (r *SelfRemediationPolicyReconciler) rollingRestart(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	action remediationv1alpha1.Action,
+	target client.Object,
+) (bool, error) {
+	log := log.FromContext(ctx).WithValues("target", client.ObjectKeyFromObject(target).String())
+	workload, ok := asScalableWorkload(target)
+	if !ok {
+		return false, fmt.Errorf("rolling restart requires a Deployment or StatefulSet, got %T", target)
+	}
+	kind := targetKind(target)
+
+	restarting := workloadRestarting(target)
+	if limit := maxConcurrentRestarts(action.RestartParams); restarting >= limit {
+		log.Info("Skipping action: rollout already in progress", "restarting", restarting, "max_concurrent_restarts", limit)
+		r.Recorder.Eventf(policy, corev1.EventTypeNormal, "RestartLimited",
+			"Rolling restart of %s %s/%s skipped: %d pods are already restarting (max %d)",
+			kind, target.GetNamespace(), target.GetName(), restarting, limit)
+		return false, nil
+	}
+
+	if _, err := r.snapshot(ctx, policy, action.Type, target, 0, templateAnnotationsPath); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	stampRestart(workload.template, now)
+	markRemediated(target, now)
+
+	if err := r.Update(ctx, target); err != nil {
+		return false, fmt.Errorf("failed to restart %s: %w", kind, err)
+	}
+
+	log.Info("Started rolling restart")
+	r.Recorder.Eventf(policy, corev1.EventTypeNormal, "RollingRestart",
+		"Started rolling restart of %s %s/%s", kind, target.GetNamespace(), target.GetName())
+	return true, nil
+}
+
+// stampRestart sets the restart annotation of the pod template, which replaces every pod
+// of the workload
+func stampRestart(template *corev1.PodTemplateSpec, now time.Time) {
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string)
+	}
+	template.Annotations[restartedAtAnnotation] = now.UTC().Format(time.RFC3339)
+}
+
+// workloadRestarting estimates how many pods of the workload are being replaced or unavailable
+func workloadRestarting(target client.Object) int32 {
+	var desired, updated, ready int32
+	switch workload := target.(type) {
+	case *appsv1.Deployment:
+		desired = replicasOrDefault(workload.Spec.Replicas)
+		if workload.Status.ObservedGeneration < workload.Generation {
+			return desired
+		}
+		updated, ready = workload.Status.UpdatedReplicas, workload.Status.AvailableReplicas
+	case *appsv1.StatefulSet:
+		desired = replicasOrDefault(workload.Spec.Replicas)
+		if workload.Status.ObservedGeneration < workload.Generation {
+			return desired
+		}
+		updated, ready = workload.Status.UpdatedReplicas, workload.Status.ReadyReplicas
+	default:
+		return 0
+	}
+
+	restarting := desired - ready
+	if pending := desired - updated; pending > restarting {
+		restarting = pending
+	}
+	if restarting < 0 {
+		return 0
+	}
+	return restarting
+}
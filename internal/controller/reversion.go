@@ -0,0 +1,513 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// Temporary changes are recorded on the changed resource itself, in the same update that
+// applies them, so a pending reversion survives controller restarts and leader changes.
+const (
+	// pendingRevertLabel marks resources that carry a temporary change awaiting reversion
+	pendingRevertLabel = "kubemedic.io/pending-revert"
+	// revertAtAnnotation holds the RFC3339 deadline of the reversion
+	revertAtAnnotation = "kubemedic.io/revert-at"
+	// revertPolicyAnnotation holds the namespace/name of the policy that owns the reversion
+	revertPolicyAnnotation = "kubemedic.io/revert-policy"
+	// revertRuleAnnotation holds the rule whose conditions triggered the change
+	revertRuleAnnotation = "kubemedic.io/revert-rule"
+	// revertStrategyAnnotation, revertStepSizeAnnotation and revertStepIntervalAnnotation
+	// describe how the change is walked back
+	revertStrategyAnnotation     = "kubemedic.io/revert-strategy"
+	revertStepSizeAnnotation     = "kubemedic.io/revert-step-size"
+	revertStepIntervalAnnotation = "kubemedic.io/revert-step-interval"
+	// revertPathsAnnotation lists the fields changed by the pending temporary change; only
+	// these are restored from the backup
+	revertPathsAnnotation = "kubemedic.io/revert-paths"
+
+	// reversionFinalizer ensures pending reversions are applied before a policy is deleted
+	reversionFinalizer = "remediation.kubemedic.io/reversion"
+
+	// Paths of the fields changed by actions, recorded in revertPathsAnnotation and in backups
+	replicasPath            = "spec.replicas"
+	maxReplicasPath         = "spec.maxReplicas"
+	resourcesPath           = "spec.template.spec.containers[*].resources"
+	templatePath            = "spec.template"
+	templateAnnotationsPath = "spec.template.metadata.annotations"
+
+	defaultRevertStepSize     = int32(1)
+	defaultRevertStepInterval = time.Minute
+)
+
+// revertPlan describes how a temporary change is walked back
+type revertPlan struct {
+	gradual  bool
+	stepSize int32
+	interval time.Duration
+	rule     string
+}
+
+// revertPlanFor reads the revert plan recorded on the object. Missing or unreadable
+// step settings fall back to the defaults.
+func revertPlanFor(obj metav1.Object) revertPlan {
+	annotations := obj.GetAnnotations()
+	plan := revertPlan{
+		gradual:  annotations[revertStrategyAnnotation] == remediationv1alpha1.RevertGradual,
+		stepSize: defaultRevertStepSize,
+		interval: defaultRevertStepInterval,
+		rule:     annotations[revertRuleAnnotation],
+	}
+	if size, err := strconv.ParseInt(annotations[revertStepSizeAnnotation], 10, 32); err == nil && size > 0 {
+		plan.stepSize = int32(size)
+	}
+	if interval, err := time.ParseDuration(annotations[revertStepIntervalAnnotation]); err == nil && interval > 0 {
+		plan.interval = interval
+	}
+	return plan
+}
+
+// next returns the value for the next revert step and whether it completes the revert.
+// Gradual steps move towards the original from either direction.
+func (p revertPlan) next(current, original int32) (int32, bool) {
+	if !p.gradual {
+		return original, true
+	}
+	switch {
+	case current-p.stepSize > original:
+		return current - p.stepSize, false
+	case current+p.stepSize < original:
+		return current + p.stepSize, false
+	}
+	return original, true
+}
+
+// scalingDuration parses ScalingParams.ScalingDuration. A zero duration means the
+// change is not reverted automatically.
+func scalingDuration(params *remediationv1alpha1.ScalingParameters) (time.Duration, error) {
+	if params == nil || params.ScalingDuration == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(params.ScalingDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid scaling duration %q: %w", params.ScalingDuration, err)
+	}
+	return duration, nil
+}
+
+// hasPendingReversion reports whether the object already carries a temporary change
+func hasPendingReversion(obj metav1.Object) bool {
+	return obj.GetLabels()[pendingRevertLabel] == "true"
+}
+
+// scheduleReversion records on the object that its temporary change must be reverted
+// by the policy once the duration has elapsed
+func scheduleReversion(
+	obj metav1.Object,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	rule string,
+	params *remediationv1alpha1.ScalingParameters,
+	duration time.Duration,
+	now time.Time,
+) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[pendingRevertLabel] = "true"
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[revertAtAnnotation] = now.Add(duration).UTC().Format(time.RFC3339)
+	annotations[revertPolicyAnnotation] = types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}.String()
+	annotations[revertRuleAnnotation] = rule
+	delete(annotations, revertStrategyAnnotation)
+	delete(annotations, revertStepSizeAnnotation)
+	delete(annotations, revertStepIntervalAnnotation)
+	if params != nil && params.RevertStrategy == remediationv1alpha1.RevertGradual {
+		annotations[revertStrategyAnnotation] = remediationv1alpha1.RevertGradual
+		if params.RevertStepSize != nil {
+			annotations[revertStepSizeAnnotation] = strconv.Itoa(int(*params.RevertStepSize))
+		}
+		if params.RevertStepInterval != "" {
+			annotations[revertStepIntervalAnnotation] = params.RevertStepInterval
+		}
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// addRevertPaths records fields changed by a temporary change
+func addRevertPaths(obj metav1.Object, paths ...string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	recorded := revertPaths(obj)
+	for _, path := range paths {
+		recorded[path] = true
+	}
+	sorted := make([]string, 0, len(recorded))
+	for path := range recorded {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+	annotations[revertPathsAnnotation] = strings.Join(sorted, ",")
+	obj.SetAnnotations(annotations)
+}
+
+// revertPaths returns the fields changed by the pending temporary change
+func revertPaths(obj metav1.Object) map[string]bool {
+	paths := make(map[string]bool)
+	for _, path := range strings.Split(obj.GetAnnotations()[revertPathsAnnotation], ",") {
+		if path != "" {
+			paths[path] = true
+		}
+	}
+	return paths
+}
+
+// rescheduleReversion moves the deadline of an in-progress reversion
+func rescheduleReversion(obj metav1.Object, revertAt time.Time) {
+	annotations := obj.GetAnnotations()
+	annotations[revertAtAnnotation] = revertAt.UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
+
+// trackPendingReversion adds or updates a scheduled reversion in the policy status
+func trackPendingReversion(
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	kind string,
+	obj metav1.Object,
+	revertAt time.Time,
+) {
+	entry := remediationv1alpha1.PendingReversion{
+		Kind:      kind,
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		RevertAt:  metav1.NewTime(revertAt),
+	}
+	for i, existing := range policy.Status.PendingReversions {
+		if existing.Kind == kind && existing.Namespace == entry.Namespace && existing.Name == entry.Name {
+			policy.Status.PendingReversions[i] = entry
+			return
+		}
+	}
+	policy.Status.PendingReversions = append(policy.Status.PendingReversions, entry)
+}
+
+// clearReversion removes the reversion bookkeeping once the change has been reverted
+func clearReversion(obj metav1.Object) {
+	labels := obj.GetLabels()
+	delete(labels, pendingRevertLabel)
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, revertAtAnnotation)
+	delete(annotations, revertPolicyAnnotation)
+	delete(annotations, revertRuleAnnotation)
+	delete(annotations, revertStrategyAnnotation)
+	delete(annotations, revertStepSizeAnnotation)
+	delete(annotations, revertStepIntervalAnnotation)
+	delete(annotations, revertPathsAnnotation)
+	delete(annotations, resizeModeAnnotation)
+	delete(annotations, backupAnnotation)
+	obj.SetAnnotations(annotations)
+}
+
+// reversionDue returns whether the object's reversion is due and, if not, how long remains
+func reversionDue(obj metav1.Object, now time.Time) (bool, time.Duration) {
+	revertAt, err := time.Parse(time.RFC3339, obj.GetAnnotations()[revertAtAnnotation])
+	if err != nil {
+		// An unreadable deadline must not leave the change in place forever
+		return true, 0
+	}
+	if remaining := revertAt.Sub(now); remaining > 0 {
+		return false, remaining
+	}
+	return true, 0
+}
+
+// ownedByPolicy reports whether the object's pending reversion belongs to the policy
+func ownedByPolicy(obj metav1.Object, policy *remediationv1alpha1.SelfRemediationPolicy) bool {
+	owner := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}.String()
+	return obj.GetAnnotations()[revertPolicyAnnotation] == owner
+}
+
+// reconcileReversions reverts every due temporary change made by the policy, or all of
+// them when force is set, and refreshes status.pendingReversions. It returns the time
+// until the next pending reversion is due, or zero when none remain.
+func (r *SelfRemediationPolicyReconciler) reconcileReversions(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	force bool,
+) (time.Duration, error) {
+	log := log.FromContext(ctx)
+	now := time.Now()
+
+	var pending []remediationv1alpha1.PendingReversion
+	var next time.Duration
+	track := func(kind string, obj metav1.Object, remaining time.Duration) {
+		revertAt := metav1.NewTime(now.Add(remaining))
+		pending = append(pending, remediationv1alpha1.PendingReversion{
+			Kind:      kind,
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			RevertAt:  revertAt,
+		})
+		if next == 0 || remaining < next {
+			next = remaining
+		}
+	}
+
+	// Changes may have been made in any namespace the policy's pods resolved to, so
+	// search the whole cluster for the policy's pending reversions
+	candidates, err := r.listPendingReversions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, obj := range candidates {
+		if !ownedByPolicy(obj, policy) {
+			continue
+		}
+		kind := targetKind(obj)
+		if due, remaining := reversionDue(obj, now); !due && !force {
+			track(kind, obj, remaining)
+			continue
+		}
+		remaining, err := r.revertObject(ctx, policy, obj, force)
+		if err != nil {
+			return 0, err
+		}
+		if remaining > 0 {
+			track(kind, obj, remaining)
+			continue
+		}
+		log.Info("Reverted temporary change",
+			"kind", kind,
+			"target", client.ObjectKeyFromObject(obj).String())
+	}
+
+	policy.Status.PendingReversions = pending
+	return next, nil
+}
+
+// listPendingReversions returns every resource in the cluster labelled as pending reversion
+func (r *SelfRemediationPolicyReconciler) listPendingReversions(ctx context.Context) ([]client.Object, error) {
+	opts := []client.ListOption{client.MatchingLabels{pendingRevertLabel: "true"}}
+	var objects []client.Object
+
+	var deployments appsv1.DeploymentList
+	if err := r.List(ctx, &deployments, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list deployments pending reversion: %w", err)
+	}
+	for i := range deployments.Items {
+		objects = append(objects, &deployments.Items[i])
+	}
+
+	var statefulSets appsv1.StatefulSetList
+	if err := r.List(ctx, &statefulSets, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets pending reversion: %w", err)
+	}
+	for i := range statefulSets.Items {
+		objects = append(objects, &statefulSets.Items[i])
+	}
+
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if err := r.List(ctx, &hpas, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list HPAs pending reversion: %w", err)
+	}
+	for i := range hpas.Items {
+		objects = append(objects, &hpas.Items[i])
+	}
+
+	return objects, nil
+}
+
+// targetKind returns the kind name used in status and events for a remediated resource
+func targetKind(obj client.Object) string {
+	switch obj.(type) {
+	case *appsv1.Deployment:
+		return "Deployment"
+	case *appsv1.StatefulSet:
+		return "StatefulSet"
+	case *autoscalingv2.HorizontalPodAutoscaler:
+		return "HorizontalPodAutoscaler"
+	case *corev1.Pod:
+		return "Pod"
+	default:
+		return obj.GetObjectKind().GroupVersionKind().Kind
+	}
+}
+
+// revertObject walks a temporary change back towards the state saved in the object's
+// backup. Immediate reverts, and all reverts when immediate is set, restore the original in
+// one step. Gradual reverts move one step at a time and hold while the triggering rule is
+// breaching again. It returns the time until the next step, or zero once reverted.
+func (r *SelfRemediationPolicyReconciler) revertObject(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	obj client.Object,
+	immediate bool,
+) (time.Duration, error) {
+	original, err := r.originalState(ctx, policy, obj)
+	if err != nil {
+		return 0, err
+	}
+
+	// Resource changes are always restored in one step
+	paths := revertPaths(obj)
+	if paths[resourcesPath] {
+		if err := r.revertResources(ctx, obj, original); err != nil {
+			return 0, err
+		}
+	}
+	if !paths[replicasPath] && !paths[maxReplicasPath] {
+		// Leave replica counts alone unless the change scaled the object
+		original = nil
+	}
+
+	switch target := obj.(type) {
+	case *appsv1.Deployment:
+		var originalReplicas *int32
+		if original, ok := original.(*appsv1.Deployment); ok {
+			replicas := replicasOrDefault(original.Spec.Replicas)
+			originalReplicas = &replicas
+		}
+		return r.revertScale(ctx, policy, target, "replicas", replicasOrDefault(target.Spec.Replicas),
+			originalReplicas, func(v int32) { target.Spec.Replicas = &v }, immediate)
+
+	case *appsv1.StatefulSet:
+		var originalReplicas *int32
+		if original, ok := original.(*appsv1.StatefulSet); ok {
+			replicas := replicasOrDefault(original.Spec.Replicas)
+			originalReplicas = &replicas
+		}
+		return r.revertScale(ctx, policy, target, "replicas", replicasOrDefault(target.Spec.Replicas),
+			originalReplicas, func(v int32) { target.Spec.Replicas = &v }, immediate)
+
+	case *autoscalingv2.HorizontalPodAutoscaler:
+		var originalMax *int32
+		if original, ok := original.(*autoscalingv2.HorizontalPodAutoscaler); ok {
+			originalMax = &original.Spec.MaxReplicas
+		}
+		return r.revertScale(ctx, policy, target, "maxReplicas", target.Spec.MaxReplicas,
+			originalMax, func(v int32) { target.Spec.MaxReplicas = v }, immediate)
+
+	default:
+		return 0, fmt.Errorf("cannot revert %T %s", obj, client.ObjectKeyFromObject(obj))
+	}
+}
+
+// revertScale moves a replica count (or limit) from current towards original, applying it
+// with set. Without a known original only the reversion bookkeeping is cleared.
+func (r *SelfRemediationPolicyReconciler) revertScale(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	obj client.Object,
+	field string,
+	current int32,
+	original *int32,
+	set func(int32),
+	immediate bool,
+) (time.Duration, error) {
+	plan := revertPlanFor(obj)
+	plan.gradual = plan.gradual && !immediate
+	kind := targetKind(obj)
+
+	if original != nil {
+		if wait, held := r.holdGradualRevert(policy, plan, kind, obj); held {
+			if err := r.updateReverted(ctx, obj); err != nil {
+				return 0, err
+			}
+			return wait, nil
+		}
+
+		value, done := plan.next(current, *original)
+		set(value)
+		if !done {
+			rescheduleReversion(obj, time.Now().Add(plan.interval))
+			r.Recorder.Eventf(policy, corev1.EventTypeNormal, "RevertStep",
+				"Gradually reverting %s %s/%s: %s %d -> %d (original %d)",
+				kind, obj.GetNamespace(), obj.GetName(), field, current, value, *original)
+			if err := r.updateReverted(ctx, obj); err != nil {
+				return 0, err
+			}
+			return plan.interval, nil
+		}
+	}
+
+	clearReversion(obj)
+	return 0, r.updateReverted(ctx, obj)
+}
+
+// replicasOrDefault returns the replica count of a workload, which defaults to 1 when unset
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// holdGradualRevert pauses a gradual revert while the rule that triggered the change is
+// breaching again. When held, the next step is pushed back by one interval.
+func (r *SelfRemediationPolicyReconciler) holdGradualRevert(
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	plan revertPlan,
+	kind string,
+	obj client.Object,
+) (time.Duration, bool) {
+	if !plan.gradual || plan.rule == "" {
+		return 0, false
+	}
+	policyKey := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}
+	if !r.breaches.RuleBreaching(policyKey, plan.rule) {
+		return 0, false
+	}
+
+	rescheduleReversion(obj, time.Now().Add(plan.interval))
+	r.Recorder.Eventf(policy, corev1.EventTypeNormal, "RevertHeld",
+		"Holding gradual revert of %s %s/%s: rule %s is breaching again",
+		kind, obj.GetNamespace(), obj.GetName(), plan.rule)
+	return plan.interval, true
+}
+
+// updateReverted persists a reverted object, ignoring objects deleted in the meantime
+func (r *SelfRemediationPolicyReconciler) updateReverted(ctx context.Context, obj client.Object) error {
+	if err := r.Update(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to revert %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+func TestRevertPlanNext(t *testing.T) {
+	tests := []struct {
+		name         string
+		plan         revertPlan
+		current      int32
+		original     int32
+		want         int32
+		wantComplete bool
+	}{
+		{name: "immediate", plan: revertPlan{stepSize: 1}, current: 10, original: 3, want: 3, wantComplete: true},
+		{name: "gradual down", plan: revertPlan{gradual: true, stepSize: 2}, current: 10, original: 3, want: 8},
+		{name: "gradual up", plan: revertPlan{gradual: true, stepSize: 2}, current: 1, original: 6, want: 3},
+		{name: "last step down lands on the original", plan: revertPlan{gradual: true, stepSize: 2}, current: 5, original: 3, want: 3, wantComplete: true},
+		{name: "last step up lands on the original", plan: revertPlan{gradual: true, stepSize: 2}, current: 4, original: 6, want: 6, wantComplete: true},
+		{name: "step larger than the remainder", plan: revertPlan{gradual: true, stepSize: 5}, current: 4, original: 3, want: 3, wantComplete: true},
+		{name: "one above the last step", plan: revertPlan{gradual: true, stepSize: 2}, current: 6, original: 3, want: 4},
+		{name: "already at the original", plan: revertPlan{gradual: true, stepSize: 1}, current: 3, original: 3, want: 3, wantComplete: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, complete := tt.plan.next(tt.current, tt.original)
+			if got != tt.want || complete != tt.wantComplete {
+				t.Errorf("next(%d, %d) = %d, %v, want %d, %v", tt.current, tt.original, got, complete, tt.want, tt.wantComplete)
+			}
+		})
+	}
+}
+
+func TestRevertPlanFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        revertPlan
+	}{
+		{
+			name: "defaults",
+			want: revertPlan{stepSize: defaultRevertStepSize, interval: defaultRevertStepInterval},
+		},
+		{
+			name: "gradual",
+			annotations: map[string]string{
+				revertStrategyAnnotation:     remediationv1alpha1.RevertGradual,
+				revertStepSizeAnnotation:     "3",
+				revertStepIntervalAnnotation: "30s",
+				revertRuleAnnotation:         "cpu",
+			},
+			want: revertPlan{gradual: true, stepSize: 3, interval: 30 * time.Second, rule: "cpu"},
+		},
+		{
+			name: "invalid steps fall back to the defaults",
+			annotations: map[string]string{
+				revertStrategyAnnotation:     remediationv1alpha1.RevertGradual,
+				revertStepSizeAnnotation:     "0",
+				revertStepIntervalAnnotation: "soon",
+			},
+			want: revertPlan{gradual: true, stepSize: defaultRevertStepSize, interval: defaultRevertStepInterval},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := revertPlanFor(obj); got != tt.want {
+				t.Errorf("revertPlanFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+const (
+	// revisionAnnotation is set by the Deployment controller on every ReplicaSet it owns
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+	// healthySinceAnnotation records when the ReplicaSet's current healthy streak started
+	healthySinceAnnotation = "kubemedic.io/healthy-since"
+	// lastHealthyAnnotation records when the ReplicaSet's healthy streak ended, because it
+	// became unhealthy or was replaced; it is absent while the streak lasts
+	lastHealthyAnnotation = "kubemedic.io/last-healthy"
+)
+
+// rollbackTarget returns the Deployment a RollbackDeployment action acts on. Actions
+// without a target roll back the Deployment owning the pod from the policy's TargetRef.
+func (r *SelfRemediationPolicyReconciler) rollbackTarget(
+	ctx context.Context,
+	action remediationv1alpha1.Action,
+	pod *corev1.Pod,
+) (client.Object, error) {
+	switch action.Target.Kind {
+	case "Deployment":
+		return r.getActionTarget(ctx, action)
+	case "":
+		if pod == nil {
+			return nil, fmt.Errorf("no deployment to roll back")
+		}
+		workload, err := r.podWorkload(ctx, pod)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := workload.(*appsv1.Deployment); !ok {
+			return nil, fmt.Errorf("pod %s/%s is not owned by a Deployment", pod.Namespace, pod.Name)
+		}
+		return workload, nil
+	default:
+		return nil, fmt.Errorf("unsupported target kind %q for action %s", action.Target.Kind, action.Type)
+	}
+}
+
+// minHealthyDuration parses RollbackParameters.MinHealthyDuration, treating an empty value as no requirement
+func minHealthyDuration(params *remediationv1alpha1.RollbackParameters) (time.Duration, error) {
+	if params == nil || params.MinHealthyDuration == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(params.MinHealthyDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid min healthy duration %q: %w", params.MinHealthyDuration, err)
+	}
+	return duration, nil
+}
+
+// deploymentReplicaSets returns the ReplicaSets controlled by the deployment, newest revision first
+func (r *SelfRemediationPolicyReconciler) deploymentReplicaSets(
+	ctx context.Context,
+	deployment *appsv1.Deployment,
+) ([]*appsv1.ReplicaSet, error) {
+	var replicaSets appsv1.ReplicaSetList
+	if err := r.List(ctx, &replicaSets, client.InNamespace(deployment.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list ReplicaSets: %w", err)
+	}
+
+	var owned []*appsv1.ReplicaSet
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if owner := metav1.GetControllerOf(rs); owner != nil && owner.UID == deployment.UID {
+			owned = append(owned, rs)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return replicaSetRevision(owned[i]) > replicaSetRevision(owned[j])
+	})
+	return owned, nil
+}
+
+// replicaSetRevision returns the deployment revision of the ReplicaSet, or zero when unknown
+func replicaSetRevision(rs *appsv1.ReplicaSet) int64 {
+	revision, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// currentReplicaSet returns the ReplicaSet running the deployment's current pod template
+func currentReplicaSet(deployment *appsv1.Deployment, replicaSets []*appsv1.ReplicaSet) *appsv1.ReplicaSet {
+	for _, rs := range replicaSets {
+		if equality.Semantic.DeepEqual(templateWithoutHash(rs.Spec.Template), deployment.Spec.Template) {
+			return rs
+		}
+	}
+	return nil
+}
+
+// templateWithoutHash strips the pod-template-hash label the Deployment controller adds
+// to ReplicaSet templates, so the template can be compared with or restored to a Deployment
+func templateWithoutHash(template corev1.PodTemplateSpec) corev1.PodTemplateSpec {
+	template = *template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	return template
+}
+
+// deploymentHealthy reports whether every replica of the deployment runs its current
+// template and is available
+func deploymentHealthy(deployment *appsv1.Deployment) bool {
+	desired := replicasOrDefault(deployment.Spec.Replicas)
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == desired &&
+		deployment.Status.AvailableReplicas == desired &&
+		deployment.Status.UnavailableReplicas == 0
+}
+
+// observeDeploymentHealth records on the deployment's ReplicaSets how long they were
+// continuously healthy, so later rollbacks can prefer revisions with a proven record. The
+// ReplicaSets are only written when a healthy streak starts or ends.
+func (r *SelfRemediationPolicyReconciler) observeDeploymentHealth(ctx context.Context, deployment *appsv1.Deployment) error {
+	replicaSets, err := r.deploymentReplicaSets(ctx, deployment)
+	if err != nil {
+		return err
+	}
+	current := currentReplicaSet(deployment, replicaSets)
+	healthy := deploymentHealthy(deployment)
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	for _, rs := range replicaSets {
+		annotations := rs.GetAnnotations()
+		_, streak := annotations[healthySinceAnnotation]
+		_, ended := annotations[lastHealthyAnnotation]
+		ongoing := streak && !ended
+
+		patch := client.MergeFrom(rs.DeepCopy())
+		switch {
+		case current != nil && rs.UID == current.UID && healthy && !ongoing:
+			// A new streak starts
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[healthySinceAnnotation] = now
+			delete(annotations, lastHealthyAnnotation)
+		case ongoing && (current == nil || rs.UID != current.UID || !healthy):
+			// The streak ends, because the revision became unhealthy or was replaced
+			annotations[lastHealthyAnnotation] = now
+		default:
+			continue
+		}
+		rs.SetAnnotations(annotations)
+
+		if err := r.Patch(ctx, rs, patch); err != nil {
+			return fmt.Errorf("failed to record health of ReplicaSet %s/%s: %w", rs.Namespace, rs.Name, err)
+		}
+	}
+	return nil
+}
+
+// observeRollbackTargets records the health of every Deployment the policy may roll back
+func (r *SelfRemediationPolicyReconciler) observeRollbackTargets(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	pods []corev1.Pod,
+) {
+	log := log.FromContext(ctx)
+	observed := make(map[client.ObjectKey]bool)
+	observe := func(target client.Object, err error) {
+		if err != nil {
+			log.V(1).Info("Unable to resolve rollback target", "error", err.Error())
+			return
+		}
+		key := client.ObjectKeyFromObject(target)
+		if observed[key] {
+			return
+		}
+		observed[key] = true
+		if err := r.observeDeploymentHealth(ctx, target.(*appsv1.Deployment)); err != nil {
+			log.Error(err, "Failed to record deployment health")
+		}
+	}
+
+	// Pods of the same ReplicaSet belong to the same deployment, so each owner is resolved once
+	owners := make(map[types.UID]bool)
+	for _, rule := range policy.Spec.Rules {
+		for _, action := range rule.Actions {
+			if action.Type != remediationv1alpha1.RollbackDeployment {
+				continue
+			}
+			if action.Target.Kind != "" {
+				observe(r.rollbackTarget(ctx, action, nil))
+				continue
+			}
+			// Pods of a selector may belong to several deployments
+			for i := range pods {
+				if owner := metav1.GetControllerOf(&pods[i]); owner != nil {
+					if owners[owner.UID] {
+						continue
+					}
+					owners[owner.UID] = true
+				}
+				observe(r.rollbackTarget(ctx, action, &pods[i]))
+			}
+		}
+	}
+}
+
+// healthyFor returns how long the ReplicaSet was observed continuously healthy: the length
+// of its last streak, or of the current one up to now
+func healthyFor(rs *appsv1.ReplicaSet, now time.Time) time.Duration {
+	since, err := time.Parse(time.RFC3339, rs.Annotations[healthySinceAnnotation])
+	if err != nil {
+		return 0
+	}
+	last, ok := rs.Annotations[lastHealthyAnnotation]
+	if !ok {
+		return now.Sub(since)
+	}
+	end, err := time.Parse(time.RFC3339, last)
+	if err != nil {
+		return 0
+	}
+	return end.Sub(since)
+}
+
+// rollbackRevision returns the newest ReplicaSet older than the current one that was
+// observed healthy for at least minHealthy, or nil. Revisions never observed healthy are
+// not eligible, or repeated breaches could bounce between a bad revision and the current one.
+func rollbackRevision(
+	current *appsv1.ReplicaSet,
+	replicaSets []*appsv1.ReplicaSet,
+	minHealthy time.Duration,
+	now time.Time,
+) *appsv1.ReplicaSet {
+	currentRevision := replicaSetRevision(current)
+	for _, rs := range replicaSets {
+		if rs.UID == current.UID || replicaSetRevision(rs) >= currentRevision {
+			continue
+		}
+		if healthy := healthyFor(rs, now); healthy <= 0 || healthy < minHealthy {
+			continue
+		}
+		return rs
+	}
+	return nil
+}
+
+// rollbackDeployment restores the pod template of the newest earlier revision of the
+// deployment that was observed healthy, for at least the configured minimum duration if set.
+// The deployment is backed up before it is changed. It reports whether a rollback was made.
+func (r *SelfRemediationPolicyReconciler) rollbackDeployment(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	action remediationv1alpha1.Action,
+	deployment *appsv1.Deployment,
+) (bool, error) {
+	log := log.FromContext(ctx).WithValues("deployment", client.ObjectKeyFromObject(deployment).String())
+
+	minHealthy, err := minHealthyDuration(action.RollbackParams)
+	if err != nil {
+		log.Error(err, "Skipping action: invalid rollback parameters")
+		return false, nil
+	}
+
+	replicaSets, err := r.deploymentReplicaSets(ctx, deployment)
+	if err != nil {
+		return false, err
+	}
+	current := currentReplicaSet(deployment, replicaSets)
+	if current == nil {
+		log.Info("Skipping action: current ReplicaSet not found, rollout may be in progress")
+		return false, nil
+	}
+	currentRevision := replicaSetRevision(current)
+
+	previous := rollbackRevision(current, replicaSets, minHealthy, time.Now())
+	if previous == nil {
+		log.Info("Skipping action: no eligible previous revision", "current_revision", currentRevision)
+		r.Recorder.Eventf(policy, corev1.EventTypeWarning, "RollbackSkipped",
+			"No previous revision of Deployment %s/%s is eligible for rollback", deployment.Namespace, deployment.Name)
+		return false, nil
+	}
+
+	backup, err := r.snapshot(ctx, policy, action.Type, deployment, 0, templatePath)
+	if err != nil {
+		return false, err
+	}
+
+	deployment.Spec.Template = templateWithoutHash(previous.Spec.Template)
+	markRemediated(deployment, time.Now())
+	if err := r.Update(ctx, deployment); err != nil {
+		return false, fmt.Errorf("failed to roll back deployment: %w", err)
+	}
+
+	previousRevision := replicaSetRevision(previous)
+	log.Info("Rolled back deployment",
+		"from_revision", currentRevision,
+		"to_revision", previousRevision,
+		"backup", backup.Name,
+	)
+	r.Recorder.Eventf(policy, corev1.EventTypeNormal, "RolledBack",
+		"Rolled back Deployment %s/%s from revision %d to %d (backup %s)",
+		deployment.Namespace, deployment.Name, currentRevision, previousRevision, backup.Name)
+	return true, nil
+}
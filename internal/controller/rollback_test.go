@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// testReplicaSet returns a ReplicaSet of the revision with the health annotations, if set
+func testReplicaSet(revision int64, healthySince, lastHealthy string) *appsv1.ReplicaSet {
+	annotations := map[string]string{revisionAnnotation: strconv.FormatInt(revision, 10)}
+	if healthySince != "" {
+		annotations[healthySinceAnnotation] = healthySince
+	}
+	if lastHealthy != "" {
+		annotations[lastHealthyAnnotation] = lastHealthy
+	}
+	return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:        "app-" + strconv.FormatInt(revision, 10),
+		UID:         types.UID("rs-" + strconv.FormatInt(revision, 10)),
+		Annotations: annotations,
+	}}
+}
+
+func TestRollbackRevision(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(ago time.Duration) string {
+		return now.Add(-ago).Format(time.RFC3339)
+	}
+
+	tests := []struct {
+		name         string
+		previous     []*appsv1.ReplicaSet
+		minHealthy   time.Duration
+		wantRevision int64
+	}{
+		{
+			name:     "never observed healthy",
+			previous: []*appsv1.ReplicaSet{testReplicaSet(2, "", ""), testReplicaSet(1, "", "")},
+		},
+		{
+			name:         "newest observed healthy",
+			previous:     []*appsv1.ReplicaSet{testReplicaSet(2, at(time.Hour), at(50*time.Minute)), testReplicaSet(1, at(3*time.Hour), at(2*time.Hour))},
+			wantRevision: 2,
+		},
+		{
+			name:         "skips revision never observed healthy",
+			previous:     []*appsv1.ReplicaSet{testReplicaSet(2, "", ""), testReplicaSet(1, at(3*time.Hour), at(2*time.Hour))},
+			wantRevision: 1,
+		},
+		{
+			name:     "streak ended when it started",
+			previous: []*appsv1.ReplicaSet{testReplicaSet(2, at(time.Hour), at(time.Hour))},
+		},
+		{
+			name:         "skips revision healthy for less than the minimum",
+			previous:     []*appsv1.ReplicaSet{testReplicaSet(2, at(time.Hour), at(55*time.Minute)), testReplicaSet(1, at(3*time.Hour), at(2*time.Hour))},
+			minHealthy:   10 * time.Minute,
+			wantRevision: 1,
+		},
+		{
+			name:         "healthy for exactly the minimum",
+			previous:     []*appsv1.ReplicaSet{testReplicaSet(2, at(time.Hour), at(50*time.Minute))},
+			minHealthy:   10 * time.Minute,
+			wantRevision: 2,
+		},
+		{
+			name:     "newer revision is not a rollback target",
+			previous: []*appsv1.ReplicaSet{testReplicaSet(4, at(time.Hour), at(50*time.Minute))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := testReplicaSet(3, at(5*time.Minute), "")
+			replicaSets := append([]*appsv1.ReplicaSet{current}, tt.previous...)
+			got := rollbackRevision(current, replicaSets, tt.minHealthy, now)
+			switch {
+			case got == nil && tt.wantRevision != 0:
+				t.Errorf("rollbackRevision() = nil, want revision %d", tt.wantRevision)
+			case got != nil && replicaSetRevision(got) != tt.wantRevision:
+				t.Errorf("rollbackRevision() = revision %d, want %d", replicaSetRevision(got), tt.wantRevision)
+			}
+		})
+	}
+}
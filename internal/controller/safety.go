@@ -0,0 +1,209 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+	"github.com/ikepcampbell/kubemedic/pkg/safety"
+)
+
+// checkSafetyLimits returns an error when a scaling action would change its target beyond
+// the configured safety limits. The admission webhook applies the same limits, but the
+// configuration or the target may have changed since the policy was admitted.
+func checkSafetyLimits(limits safety.Limits, action remediationv1alpha1.Action, target client.Object) error {
+	switch action.Type {
+	case remediationv1alpha1.ScaleUp, remediationv1alpha1.ScaleDown, remediationv1alpha1.AdjustHPALimits:
+	default:
+		return nil
+	}
+	params := action.ScalingParams
+	if params == nil {
+		return nil
+	}
+
+	// Invalid durations are reported when the action runs
+	if duration, err := time.ParseDuration(params.ScalingDuration); err == nil {
+		if err := limits.CheckDuration(duration); err != nil {
+			return err
+		}
+	}
+
+	if params.TemporaryMaxReplicas == nil {
+		return nil
+	}
+	var current int32
+	switch target := target.(type) {
+	case *appsv1.Deployment:
+		current = replicasOrDefault(target.Spec.Replicas)
+	case *appsv1.StatefulSet:
+		current = replicasOrDefault(target.Spec.Replicas)
+	case *autoscalingv2.HorizontalPodAutoscaler:
+		current = target.Spec.MaxReplicas
+	default:
+		return nil
+	}
+	return limits.CheckScaling(current, *params.TemporaryMaxReplicas)
+}
+
+// checkProtection returns an error when the target may not be changed: it is protected, or
+// its namespace or the acting policy's is denied, not allowed or excluded from remediation.
+// The admission webhook checks the same when the policy is admitted, but resources and
+// namespaces may be protected later, and policies admitted while the webhook was down.
+// Restores from backups are checked the same way, with the backup's namespace standing in
+// for the policy's.
+func checkProtection(
+	ctx context.Context,
+	c client.Reader,
+	settings *safety.Settings,
+	policyNamespace string,
+	target client.Object,
+) error {
+	namespaces := []string{policyNamespace}
+	if target.GetNamespace() != policyNamespace {
+		namespaces = append(namespaces, target.GetNamespace())
+	}
+	for _, name := range namespaces {
+		if err := settings.CheckNamespace(name); err != nil {
+			return err
+		}
+		var namespace corev1.Namespace
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, &namespace); err != nil {
+			return fmt.Errorf("failed to get namespace %s: %w", name, err)
+		}
+		if namespace.Labels[safety.ExcludeLabel] == "true" {
+			return fmt.Errorf("namespace %s is excluded from remediation", name)
+		}
+	}
+
+	if settings.IsProtected(target.GetLabels()) {
+		return fmt.Errorf("%s %s/%s is protected from remediation",
+			targetKind(target), target.GetNamespace(), target.GetName())
+	}
+
+	// Protecting a workload protects its pods, and the HPA scaling it
+	return checkOwnerProtection(ctx, c, settings, target)
+}
+
+// maxOwnerDepth bounds the walk up a chain of owners
+const maxOwnerDepth = 5
+
+// checkOwnerProtection returns an error when a workload the target belongs to is
+// protected: for an HPA the workload it scales, and otherwise the controllers owning the
+// target, such as the ReplicaSet and Deployment of a pod. Owners of kinds KubeMedic does
+// not read end the walk.
+func checkOwnerProtection(
+	ctx context.Context,
+	c client.Reader,
+	settings *safety.Settings,
+	target client.Object,
+) error {
+	var kind, name string
+	switch target := target.(type) {
+	case *autoscalingv2.HorizontalPodAutoscaler:
+		kind, name = target.Spec.ScaleTargetRef.Kind, target.Spec.ScaleTargetRef.Name
+	case *unstructured.Unstructured:
+		if target.GetKind() == "HorizontalPodAutoscaler" {
+			kind, _, _ = unstructured.NestedString(target.Object, "spec", "scaleTargetRef", "kind")
+			name, _, _ = unstructured.NestedString(target.Object, "spec", "scaleTargetRef", "name")
+		}
+	}
+	if name == "" {
+		if owner := metav1.GetControllerOf(target); owner != nil {
+			kind, name = owner.Kind, owner.Name
+		}
+	}
+
+	for depth := 0; name != "" && depth < maxOwnerDepth; depth++ {
+		owner := workloadObject(kind)
+		if owner == nil {
+			return nil
+		}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: target.GetNamespace(), Name: name}, owner); err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to get %s %s/%s: %w", kind, target.GetNamespace(), name, err)
+		}
+		if settings.IsProtected(owner.GetLabels()) {
+			return fmt.Errorf("%s %s/%s belongs to %s %s/%s, which is protected from remediation",
+				targetKind(target), target.GetNamespace(), target.GetName(), kind, target.GetNamespace(), name)
+		}
+
+		kind, name = "", ""
+		if ref := metav1.GetControllerOf(owner); ref != nil {
+			kind, name = ref.Kind, ref.Name
+		}
+	}
+	return nil
+}
+
+// workloadObject returns an empty object of a workload kind that can own pods, or nil
+func workloadObject(kind string) client.Object {
+	switch kind {
+	case "Deployment":
+		return &appsv1.Deployment{}
+	case "StatefulSet":
+		return &appsv1.StatefulSet{}
+	case "ReplicaSet":
+		return &appsv1.ReplicaSet{}
+	case "DaemonSet":
+		return &appsv1.DaemonSet{}
+	case "Job":
+		return &batchv1.Job{}
+	case "CronJob":
+		return &batchv1.CronJob{}
+	default:
+		return nil
+	}
+}
+
+// policiesForConfig requeues every policy when the KubeMedicConfig changes, so new limits
+// and protections take effect without waiting for the next periodic reconcile
+func (r *SelfRemediationPolicyReconciler) policiesForConfig(ctx context.Context, config client.Object) []reconcile.Request {
+	if config.GetName() != safety.ConfigName {
+		return nil
+	}
+
+	var policies remediationv1alpha1.SelfRemediationPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list policies for configuration change")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scalableWorkload gives uniform access to the replica count of the workloads
+// that scaling actions can target
+type scalableWorkload struct {
+	client.Object
+	replicas    *int32
+	podLabels   map[string]string
+	template    *corev1.PodTemplateSpec
+	selector    *metav1.LabelSelector
+	setReplicas func(int32)
+}
+
+// asScalableWorkload wraps a Deployment or StatefulSet, returning false for other kinds
+func asScalableWorkload(obj client.Object) (*scalableWorkload, bool) {
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		return &scalableWorkload{
+			Object:      workload,
+			replicas:    workload.Spec.Replicas,
+			podLabels:   workload.Spec.Template.Labels,
+			template:    &workload.Spec.Template,
+			selector:    workload.Spec.Selector,
+			setReplicas: func(n int32) { workload.Spec.Replicas = &n },
+		}, true
+	case *appsv1.StatefulSet:
+		return &scalableWorkload{
+			Object:      workload,
+			replicas:    workload.Spec.Replicas,
+			podLabels:   workload.Spec.Template.Labels,
+			template:    &workload.Spec.Template,
+			selector:    workload.Spec.Selector,
+			setReplicas: func(n int32) { workload.Spec.Replicas = &n },
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// scaleDownFloor returns the fewest replicas the workload may be scaled down to and
+// what imposes that floor. The HPA's minReplicas and every PodDisruptionBudget that
+// selects the workload's pods are honoured; the floor is never below one replica.
+func (r *SelfRemediationPolicyReconciler) scaleDownFloor(
+	ctx context.Context,
+	workload *scalableWorkload,
+	hpaMinReplicas *int32,
+) (int32, string, error) {
+	floor, reason := int32(1), "minimum of one replica"
+	if hpaMinReplicas != nil && *hpaMinReplicas > floor {
+		floor, reason = *hpaMinReplicas, "HPA minReplicas"
+	}
+
+	current := replicasOrDefault(workload.replicas)
+	pdbMin, pdbName, err := r.pdbMinAvailable(ctx, workload.GetNamespace(), workload.podLabels, current)
+	if err != nil {
+		return 0, "", err
+	}
+	if pdbMin > floor {
+		floor, reason = pdbMin, fmt.Sprintf("PodDisruptionBudget %s", pdbName)
+	}
+	return floor, reason, nil
+}
+
+// pdbMinAvailable returns the highest number of pods that must stay available across the
+// PodDisruptionBudgets selecting pods with the given labels, and the name of that budget
+func (r *SelfRemediationPolicyReconciler) pdbMinAvailable(
+	ctx context.Context,
+	namespace string,
+	podLabels map[string]string,
+	current int32,
+) (int32, string, error) {
+	var pdbs policyv1.PodDisruptionBudgetList
+	if err := r.List(ctx, &pdbs, client.InNamespace(namespace)); err != nil {
+		return 0, "", fmt.Errorf("failed to list PodDisruptionBudgets: %w", err)
+	}
+
+	var minAvailable int32
+	var name string
+	for i := range pdbs.Items {
+		pdb := &pdbs.Items[i]
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			return 0, "", fmt.Errorf("invalid selector on PodDisruptionBudget %s/%s: %w", pdb.Namespace, pdb.Name, err)
+		}
+		if !selector.Matches(labels.Set(podLabels)) {
+			continue
+		}
+
+		var required int32
+		switch {
+		case pdb.Spec.MinAvailable != nil:
+			value, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, int(current), true)
+			if err != nil {
+				return 0, "", fmt.Errorf("invalid minAvailable on PodDisruptionBudget %s/%s: %w", pdb.Namespace, pdb.Name, err)
+			}
+			required = int32(value)
+		case pdb.Spec.MaxUnavailable != nil:
+			value, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MaxUnavailable, int(current), true)
+			if err != nil {
+				return 0, "", fmt.Errorf("invalid maxUnavailable on PodDisruptionBudget %s/%s: %w", pdb.Namespace, pdb.Name, err)
+			}
+			required = current - int32(value)
+		}
+		if required > minAvailable {
+			minAvailable, name = required, pdb.Name
+		}
+	}
+	return minAvailable, name, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestPDBMinAvailable(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "checkout"}}
+	pdb := func(name string, selector *metav1.LabelSelector, minAvailable, maxUnavailable *intstr.IntOrString) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector:       selector,
+				MinAvailable:   minAvailable,
+				MaxUnavailable: maxUnavailable,
+			},
+		}
+	}
+	count := func(value int) *intstr.IntOrString {
+		v := intstr.FromInt32(int32(value))
+		return &v
+	}
+	percent := func(value string) *intstr.IntOrString {
+		v := intstr.FromString(value)
+		return &v
+	}
+
+	tests := []struct {
+		name     string
+		pdbs     []client.Object
+		want     int32
+		wantName string
+		wantErr  bool
+	}{
+		{name: "no budget"},
+		{name: "min available", pdbs: []client.Object{pdb("min", selector, count(2), nil)}, want: 2, wantName: "min"},
+		{name: "min available percentage rounds up", pdbs: []client.Object{pdb("min", selector, percent("50%"), nil)}, want: 3, wantName: "min"},
+		{name: "max unavailable", pdbs: []client.Object{pdb("max", selector, nil, count(1))}, want: 4, wantName: "max"},
+		{name: "max unavailable percentage rounds up", pdbs: []client.Object{pdb("max", selector, nil, percent("25%"))}, want: 3, wantName: "max"},
+		{
+			name: "highest of several budgets",
+			pdbs: []client.Object{pdb("low", selector, count(2), nil), pdb("high", selector, nil, count(1))},
+			want: 4, wantName: "high",
+		},
+		{
+			name: "budget of other pods",
+			pdbs: []client.Object{pdb("other", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "cart"}}, count(4), nil)},
+		},
+		{name: "budget without selector", pdbs: []client.Object{pdb("none", nil, count(4), nil)}},
+		{
+			name:    "invalid percentage",
+			pdbs:    []client.Object{pdb("min", selector, percent("half"), nil)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &SelfRemediationPolicyReconciler{Client: newTestClient(t, tt.pdbs...)}
+			got, name, err := r.pdbMinAvailable(context.Background(), "shop", map[string]string{"app": "checkout"}, 5)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("pdbMinAvailable() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want || name != tt.wantName {
+				t.Errorf("pdbMinAvailable() = %d, %q, want %d, %q", got, name, tt.want, tt.wantName)
+			}
+		})
+	}
+}
@@ -18,8 +18,8 @@ package controller
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
-	"strconv"
 	"sync"
 	"time"
 
@@ -29,13 +29,17 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/metrics/pkg/client/clientset/versioned"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/metrics/pkg/client/clientset/versioned"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+	"github.com/ikepcampbell/kubemedic/pkg/metrics"
+	"github.com/ikepcampbell/kubemedic/pkg/safety"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -43,11 +47,17 @@ import (
 // SelfRemediationPolicyReconciler reconciles a SelfRemediationPolicy object
 type SelfRemediationPolicyReconciler struct {
 	client.Client
-	Scheme         *runtime.Scheme
-	MetricsWatcher *MetricsWatcher
-	Recorder       record.EventRecorder
+	Scheme *runtime.Scheme
+	// Evaluator measures conditions; metrics-server is always available to it, and other
+	// metrics sources are added with AddSource
+	Evaluator *ConditionEvaluator
+	Recorder  record.EventRecorder
+	// RateLimiter limits how often actions are taken; no limits apply when nil
+	RateLimiter *ActionRateLimiter
 	// Track active remediations
 	activeRemediations sync.Map
+	// Track how long each policy condition has been over its threshold
+	breaches BreachTracker
 }
 
 // RemediationState tracks the state of active remediations
@@ -76,16 +86,11 @@ func NewSelfRemediationPolicyReconciler(
 		panic("recorder cannot be nil")
 	}
 
-	metricsWatcher := NewMetricsWatcher(metricsClient)
-	if metricsWatcher == nil {
-		panic("failed to create metrics watcher")
-	}
-
 	return &SelfRemediationPolicyReconciler{
-		Client:         client,
-		Scheme:         scheme,
-		MetricsWatcher: metricsWatcher,
-		Recorder:       recorder,
+		Client:    client,
+		Scheme:    scheme,
+		Evaluator: NewConditionEvaluator(metrics.NewMetricsServer(metricsClient)),
+		Recorder:  recorder,
 	}
 }
 
@@ -154,99 +159,374 @@ func (r *SelfRemediationPolicyReconciler) Reconcile(ctx context.Context, req ctr
 	var policy remediationv1alpha1.SelfRemediationPolicy
 	if err := r.Get(ctx, req.NamespacedName, &policy); err != nil {
 		if errors.IsNotFound(err) {
+			r.breaches.Forget(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "Failed to get SelfRemediationPolicy")
 		return ctrl.Result{}, err
 	}
 
-	// Get the pod referenced by the policy
-	var pod corev1.Pod
-	if err := r.Get(ctx, types.NamespacedName{
-		Namespace: policy.Spec.TargetRef.Namespace,
-		Name:      policy.Spec.TargetRef.Name,
-	}, &pod); err != nil {
-		if errors.IsNotFound(err) {
-			log.Info("Target pod not found", "pod", policy.Spec.TargetRef.Name)
-			return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+	// Restore breach history persisted in status, e.g. after a controller restart, before
+	// gradual reversions consult it
+	r.breaches.Restore(&policy)
+	r.breaches.Prune(&policy)
+
+	// Undo every temporary change before letting a deleted policy go
+	if !policy.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(&policy, reversionFinalizer) {
+			if _, err := r.reconcileReversions(ctx, &policy, true); err != nil {
+				log.Error(err, "failed to revert changes of deleted policy")
+				return ctrl.Result{}, err
+			}
+			controllerutil.RemoveFinalizer(&policy, reversionFinalizer)
+			if err := r.Update(ctx, &policy); err != nil {
+				log.Error(err, "failed to remove reversion finalizer")
+				return ctrl.Result{}, err
+			}
 		}
-		log.Error(err, "unable to fetch target Pod")
+		r.breaches.Forget(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	if controllerutil.AddFinalizer(&policy, reversionFinalizer) {
+		if err := r.Update(ctx, &policy); err != nil {
+			log.Error(err, "failed to add reversion finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Revert temporary changes whose duration has elapsed
+	requeueAfter := time.Second * 30
+	nextReversion, err := r.reconcileReversions(ctx, &policy, false)
+	if err != nil {
+		log.Error(err, "failed to process pending reversions")
 		return ctrl.Result{}, err
 	}
+	if nextReversion > 0 && nextReversion < requeueAfter {
+		requeueAfter = nextReversion
+	}
 
-	// Parse CPU threshold
-	threshold, err := strconv.ParseFloat(policy.Spec.CPUThreshold, 64)
+	// Get the live pods the policy monitors
+	pods, err := r.targetPods(ctx, &policy)
 	if err != nil {
-		log.Error(err, "failed to parse CPU threshold")
+		log.Error(err, "unable to fetch target pods")
 		return ctrl.Result{}, err
 	}
+	if len(pods) == 0 {
+		log.Info("No target pods found", "target", targetRefString(policy.Spec.TargetRef))
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	// Keep the health record of rollback candidates current
+	r.observeRollbackTargets(ctx, &policy, pods)
 
-	// Check if pod is over threshold
-	isOver, err := r.MetricsWatcher.IsPodOverThreshold(&pod, threshold)
+	cooldown, err := cooldownPeriod(&policy)
 	if err != nil {
-		log.Error(err, "failed to check pod CPU threshold")
-		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+		log.Error(err, "failed to parse cooldown period")
+		return ctrl.Result{}, err
 	}
+	settings, err := safety.Load(ctx, r.Client, policy.Namespace)
+	var invalidConfig *safety.InvalidConfigError
+	if goerrors.As(err, &invalidConfig) {
+		// Keep remediating with the defaults in place of the invalid settings
+		log.Error(err, "ignoring invalid KubeMedic configuration")
+		r.Recorder.Event(&policy, corev1.EventTypeWarning, "ConfigInvalid", invalidConfig.Error())
+	} else if err != nil {
+		log.Error(err, "failed to load KubeMedic configuration")
+		return ctrl.Result{}, err
+	}
+	pruneTargetCooldowns(&policy, time.Now())
+
+	// Evaluate each rule's conditions and fire the rules whose conditions have
+	// all been met for their declared duration
+	anyMet := false
+	evaluatedAt := time.Now()
+	ruleResults := make(map[string][]ConditionResult, len(policy.Spec.Rules))
+	for _, rule := range policy.Spec.Rules {
+		ruleLog := log.WithValues("rule", rule.Name)
+
+		_, results, err := r.Evaluator.EvaluateRule(ctx, pods, effectiveRule(&policy, rule))
+		if err != nil {
+			ruleLog.Error(err, "failed to evaluate rule conditions")
+			r.Recorder.Eventf(&policy, corev1.EventTypeWarning, "EvaluationFailed",
+				"Conditions of rule %s could not be evaluated: %v", rule.Name, err)
+			continue
+		}
+		ruleResults[rule.Name] = results
 
-	if isOver {
-		// Process remediation rules
-		for _, rule := range policy.Spec.Rules {
-			if err := r.processRule(ctx, &policy, &pod, rule); err != nil {
-				log.Error(err, "failed to process rule", "rule", rule.Name)
-				continue
+		sustained, remaining, err := r.observeRule(req.NamespacedName, rule.Name, results)
+		if err != nil {
+			ruleLog.Error(err, "failed to track rule conditions")
+			continue
+		}
+		if !sustained {
+			if remaining > 0 && remaining < requeueAfter {
+				requeueAfter = remaining
 			}
+			ruleLog.V(1).Info("Rule conditions not sustained", "remaining", remaining)
+			continue
+		}
+
+		anyMet = true
+		if remaining := policyCooldownRemaining(&policy, time.Now()); remaining > 0 {
+			ruleLog.Info("Rule conditions met but policy is in cooldown", "remaining", remaining)
+			r.Recorder.Eventf(&policy, corev1.EventTypeNormal, "CooldownActive",
+				"Rule %s suppressed: policy is in cooldown until %s",
+				rule.Name, policy.Status.NextEligibleTime.UTC().Format(time.RFC3339))
+			if remaining < requeueAfter {
+				requeueAfter = remaining
+			}
+			continue
+		}
+
+		ruleLog.Info("Rule conditions met, processing actions")
+		if err := r.processRule(ctx, &policy, remediationPod(pods, results), rule, results, cooldown, settings); err != nil {
+			ruleLog.Error(err, "failed to process rule")
+			continue
 		}
 	}
 
+	// Check whether earlier remediations helped
+	if next := r.verifyRemediations(ctx, &policy, ruleResults, evaluatedAt); next > 0 && next < requeueAfter {
+		requeueAfter = next
+	}
+
 	// Update status
-	policy.Status.LastChecked = metav1.Now()
-	policy.Status.Active = isOver
+	now := metav1.Now()
+	policy.Status.LastChecked = now
+	policy.Status.LastEvaluationTime = &now
+	policy.Status.Active = anyMet
+	policy.Status.PendingConditions = r.breaches.PendingConditions(req.NamespacedName)
 	if err := r.Status().Update(ctx, &policy); err != nil {
 		log.Error(err, "failed to update policy status")
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-func (r *SelfRemediationPolicyReconciler) processRule(ctx context.Context, policy *remediationv1alpha1.SelfRemediationPolicy, pod *corev1.Pod, rule remediationv1alpha1.Rule) error {
+// observeRule feeds the latest condition results into the breach tracker. It reports whether
+// every condition of the rule has been met for its duration and, if not, how long until
+// all of the currently met conditions become sustained.
+func (r *SelfRemediationPolicyReconciler) observeRule(
+	policy types.NamespacedName,
+	rule string,
+	results []ConditionResult,
+) (bool, time.Duration, error) {
+	if len(results) == 0 {
+		return false, 0, nil
+	}
+
+	now := time.Now()
+	allSustained := true
+	var remaining time.Duration
+	for _, result := range results {
+		sustained, left, err := r.breaches.Observe(policy, rule, result.Condition, result.Met, now)
+		if err != nil {
+			return false, 0, err
+		}
+		if !sustained {
+			allSustained = false
+		}
+		if left > remaining {
+			remaining = left
+		}
+	}
+
+	return allSustained, remaining, nil
+}
+
+// effectiveRule returns the rule to evaluate. Rules that declare no conditions fall back
+// to the policy-wide CPUThreshold as a single CPUUsage condition.
+func effectiveRule(policy *remediationv1alpha1.SelfRemediationPolicy, rule remediationv1alpha1.Rule) remediationv1alpha1.Rule {
+	if len(rule.Conditions) > 0 || policy.Spec.CPUThreshold == "" {
+		return rule
+	}
+	rule.Conditions = []remediationv1alpha1.Condition{{
+		Type:      remediationv1alpha1.CPUUsage,
+		Threshold: policy.Spec.CPUThreshold,
+	}}
+	return rule
+}
+
+func (r *SelfRemediationPolicyReconciler) processRule(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	pod *corev1.Pod,
+	rule remediationv1alpha1.Rule,
+	results []ConditionResult,
+	cooldown time.Duration,
+	settings *safety.Settings,
+) error {
+	log := log.FromContext(ctx)
+
+	// Undo the actions already applied when a later action of the rule fails
+	ctx, tx := withRuleTransaction(ctx)
+	fail := func(action remediationv1alpha1.Action, err error) error {
+		r.rollbackRule(ctx, policy, rule.Name, action.Type, tx, err)
+		return err
+	}
 
 	for _, action := range rule.Actions {
-		if action.Type == remediationv1alpha1.ScaleUp {
-			var deployment appsv1.Deployment
-			if err := r.Get(ctx, types.NamespacedName{
-				Namespace: action.Target.Namespace,
-				Name:      action.Target.Name,
-			}, &deployment); err != nil {
-				return fmt.Errorf("failed to get deployment: %w", err)
-			}
-
-			if err := r.executeActions(ctx, policy, []remediationv1alpha1.Action{action}, &deployment); err != nil {
-				return fmt.Errorf("failed to execute actions: %w", err)
-			}
-
-			// Track this remediation
-			r.trackRemediation(types.NamespacedName{
-				Namespace: policy.Namespace,
-				Name:      policy.Name,
-			}, types.NamespacedName{
-				Namespace: deployment.Namespace,
-				Name:      deployment.Name,
-			})
+		switch action.Type {
+		case remediationv1alpha1.ScaleUp, remediationv1alpha1.ScaleDown, remediationv1alpha1.AdjustHPALimits,
+			remediationv1alpha1.RestartPod, remediationv1alpha1.RollbackDeployment, remediationv1alpha1.UpdateResources:
+		default:
+			log.Info("Skipping action: action type not supported", "action_type", action.Type)
+			continue
+		}
+
+		var target client.Object
+		var err error
+		switch action.Type {
+		case remediationv1alpha1.RestartPod:
+			target, err = r.resolveRestartTarget(ctx, action, pod)
+		case remediationv1alpha1.RollbackDeployment:
+			target, err = r.rollbackTarget(ctx, action, pod)
+		case remediationv1alpha1.UpdateResources:
+			target, err = r.resourcesTarget(ctx, action, pod)
+		default:
+			target, err = r.getActionTarget(ctx, action)
+		}
+		if err != nil {
+			return fail(action, err)
+		}
+
+		// Never change protected resources or act in denied or excluded namespaces
+		if err := checkProtection(ctx, r.Client, settings, policy.Namespace, target); err != nil {
+			log.Info("Skipping action: remediation not allowed",
+				"action_type", action.Type,
+				"target", client.ObjectKeyFromObject(target).String(),
+				"reason", err.Error(),
+			)
+			r.Recorder.Eventf(policy, corev1.EventTypeWarning, "RemediationBlocked",
+				"%s on %s %s/%s skipped: %v",
+				action.Type, targetKind(target), target.GetNamespace(), target.GetName(), err)
+			continue
+		}
+
+		// Respect the cooldown of targets recently remediated by any policy
+		now := time.Now()
+		if remaining := targetCooldownRemaining(target, cooldown, now); remaining > 0 {
+			nextEligible := metav1.NewTime(now.Add(remaining))
+			log.Info("Skipping action: target is in cooldown",
+				"action_type", action.Type,
+				"target", client.ObjectKeyFromObject(target).String(),
+				"remaining", remaining,
+			)
+			r.Recorder.Eventf(policy, corev1.EventTypeNormal, "CooldownActive",
+				"%s on %s %s/%s suppressed: target is in cooldown until %s",
+				action.Type, targetKind(target), target.GetNamespace(), target.GetName(),
+				nextEligible.UTC().Format(time.RFC3339))
+			upsertTargetCooldown(policy, targetKind(target), target,
+				metav1.NewTime(nextEligible.Add(-cooldown)), nextEligible)
+			continue
 		}
+
+		// Stay within the configured safety limits
+		if err := checkSafetyLimits(settings.Limits, action, target); err != nil {
+			log.Info("Skipping action: safety limit exceeded",
+				"action_type", action.Type,
+				"target", client.ObjectKeyFromObject(target).String(),
+				"reason", err.Error(),
+			)
+			r.Recorder.Eventf(policy, corev1.EventTypeWarning, "SafetyLimitExceeded",
+				"%s on %s %s/%s skipped: %v",
+				action.Type, targetKind(target), target.GetNamespace(), target.GetName(), err)
+			continue
+		}
+
+		// Respect the action rate limits
+		scopes := rateLimitScopes(settings, policy, target)
+		if r.RateLimiter != nil {
+			scope, err := r.RateLimiter.Check(ctx, scopes, now)
+			if err != nil {
+				log.Error(err, "Skipping action: unable to check rate limits", "action_type", action.Type)
+				continue
+			}
+			if scope != nil {
+				log.Info("Skipping action: rate limited",
+					"action_type", action.Type,
+					"target", client.ObjectKeyFromObject(target).String(),
+					"scope", scope.name,
+					"limit", scope.limit,
+				)
+				actionsRateLimited.WithLabelValues(policy.Namespace, scope.name).Inc()
+				r.Recorder.Eventf(policy, corev1.EventTypeWarning, "RateLimited",
+					"%s on %s %s/%s suppressed: %s limit of %d actions per hour reached",
+					action.Type, targetKind(target), target.GetNamespace(), target.GetName(), scope.name, scope.limit)
+				continue
+			}
+		}
+
+		applied, err := r.executeActions(ctx, policy, rule.Name, []remediationv1alpha1.Action{action}, target)
+		if err != nil {
+			return fail(action, fmt.Errorf("failed to execute actions: %w", err))
+		}
+		if !applied {
+			tx.discard()
+			continue
+		}
+		backups := tx.commit()
+		recordRemediation(policy, action, target, cooldown, now)
+		actionsTotal.WithLabelValues(policy.Namespace, string(action.Type)).Inc()
+		if r.RateLimiter != nil {
+			if err := r.RateLimiter.Take(ctx, scopes, now); err != nil {
+				log.Error(err, "Failed to record action in rate limiter", "action_type", action.Type)
+			}
+		}
+		if action.Verification != nil {
+			if err := startVerification(policy, rule.Name, action, target, backups, results, now); err != nil {
+				log.Error(err, "Not verifying action", "action_type", action.Type)
+			}
+		}
+
+		// Track this remediation
+		r.trackRemediation(types.NamespacedName{
+			Namespace: policy.Namespace,
+			Name:      policy.Name,
+		}, client.ObjectKeyFromObject(target))
 	}
 
 	return nil
 }
 
+// getActionTarget fetches the resource referenced by an action's target
+func (r *SelfRemediationPolicyReconciler) getActionTarget(
+	ctx context.Context,
+	action remediationv1alpha1.Action,
+) (client.Object, error) {
+	var target client.Object
+	switch action.Target.Kind {
+	case "Deployment":
+		target = &appsv1.Deployment{}
+	case "StatefulSet":
+		target = &appsv1.StatefulSet{}
+	case "HorizontalPodAutoscaler", "HPA":
+		target = &autoscalingv2.HorizontalPodAutoscaler{}
+	default:
+		return nil, fmt.Errorf("unsupported target kind %q for action %s", action.Target.Kind, action.Type)
+	}
+
+	if err := r.Get(ctx, types.NamespacedName{
+		Namespace: action.Target.Namespace,
+		Name:      action.Target.Name,
+	}, target); err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", action.Target.Kind, err)
+	}
+	return target, nil
+}
+
 func (r *SelfRemediationPolicyReconciler) executeActions(
 	ctx context.Context,
 	policy *remediationv1alpha1.SelfRemediationPolicy,
+	rule string,
 	actions []remediationv1alpha1.Action,
-	deployment *appsv1.Deployment,
-) error {
+	target client.Object,
+) (bool, error) {
 	log := log.FromContext(ctx)
+	deployment, _ := target.(*appsv1.Deployment)
 
+	applied := false
 	for _, action := range actions {
 		actionLog := log.WithValues(
 			"action_type", action.Type,
@@ -267,25 +547,23 @@ func (r *SelfRemediationPolicyReconciler) executeActions(
 				actionLog.Info("Skipping action: temporary max replicas not set")
 				continue
 			}
-
-			// Store original replicas for later reversion
-			originalReplicas := deployment.Spec.Replicas
-			if originalReplicas == nil {
-				// Set default if not specified
-				defaultReplicas := int32(1)
-				originalReplicas = &defaultReplicas
+			if deployment == nil {
+				actionLog.Info("Skipping action: ScaleUp requires a Deployment target")
+				continue
 			}
-
-			if deployment.Annotations == nil {
-				deployment.Annotations = make(map[string]string)
+			revertAfter, err := scalingDuration(action.ScalingParams)
+			if err != nil {
+				actionLog.Error(err, "Skipping action: invalid scaling duration")
+				continue
 			}
-			deployment.Annotations["kubemedic.io/original-replicas"] = fmt.Sprintf("%d", *originalReplicas)
+
+			originalReplicas := replicasOrDefault(deployment.Spec.Replicas)
 
 			// If this Deployment is controlled by an HPA, don't fight it.
 			// If the requested replicas exceed HPA maxReplicas, the HPA controller will clamp it back down.
 			hpa, err := r.resolveHPAForAction(ctx, action, deployment)
 			if err != nil {
-				return err
+				return applied, err
 			}
 			if hpa != nil && *action.ScalingParams.TemporaryMaxReplicas > hpa.Spec.MaxReplicas {
 				msg := fmt.Sprintf("ScaleUp requested replicas=%d but HPA %s/%s maxReplicas=%d; skipping ScaleUp (use AdjustHPALimits instead)",
@@ -301,26 +579,144 @@ func (r *SelfRemediationPolicyReconciler) executeActions(
 				continue
 			}
 
+			// Back up the deployment so the original replicas can be restored
+			if _, err := r.snapshot(ctx, policy, action.Type, deployment, revertAfter, replicasPath); err != nil {
+				return applied, err
+			}
+
 			// Scale up
 			newReplicas := *action.ScalingParams.TemporaryMaxReplicas
 			deployment.Spec.Replicas = &newReplicas
+			now := time.Now()
+			markRemediated(deployment, now)
+			if revertAfter > 0 {
+				scheduleReversion(deployment, policy, rule, action.ScalingParams, revertAfter, now)
+			}
 
 			actionLog.Info("Scaling up deployment",
-				"original_replicas", *originalReplicas,
+				"original_replicas", originalReplicas,
 				"new_replicas", newReplicas,
 				"scaling_duration", action.ScalingParams.ScalingDuration,
 			)
 
 			if err := r.Update(ctx, deployment); err != nil {
 				actionLog.Error(err, "Failed to scale deployment")
-				return fmt.Errorf("failed to scale deployment: %v", err)
+				return applied, fmt.Errorf("failed to scale deployment: %v", err)
+			}
+			applied = true
+			if revertAfter > 0 {
+				trackPendingReversion(policy, "Deployment", deployment, now.Add(revertAfter))
 			}
 
-			// Schedule reversion if duration is specified
-			if action.ScalingParams.ScalingDuration != "" {
-				duration, _ := time.ParseDuration(action.ScalingParams.ScalingDuration)
-				go r.scheduleReversion(deployment, duration)
+		case remediationv1alpha1.ScaleDown:
+			if action.ScalingParams == nil {
+				actionLog.Info("Skipping action: scaling parameters not configured")
+				continue
 			}
+			if action.ScalingParams.TemporaryMaxReplicas == nil {
+				actionLog.Info("Skipping action: temporary max replicas not set")
+				continue
+			}
+			workload, ok := asScalableWorkload(target)
+			if !ok {
+				actionLog.Info("Skipping action: ScaleDown requires a Deployment or StatefulSet target")
+				continue
+			}
+			revertAfter, err := scalingDuration(action.ScalingParams)
+			if err != nil {
+				actionLog.Error(err, "Skipping action: invalid scaling duration")
+				continue
+			}
+
+			currentReplicas := replicasOrDefault(workload.replicas)
+			newReplicas := *action.ScalingParams.TemporaryMaxReplicas
+
+			// Never scale below the HPA's minReplicas or what the PodDisruptionBudgets require
+			hpa, err := r.resolveHPAForAction(ctx, action, workload.Object)
+			if err != nil {
+				return applied, err
+			}
+			var hpaMinReplicas *int32
+			if hpa != nil {
+				hpaMinReplicas = hpa.Spec.MinReplicas
+			}
+			floor, reason, err := r.scaleDownFloor(ctx, workload, hpaMinReplicas)
+			if err != nil {
+				return applied, err
+			}
+			if newReplicas < floor {
+				actionLog.Info("Limiting scale down",
+					"requested_replicas", newReplicas,
+					"floor", floor,
+					"reason", reason,
+				)
+				r.Recorder.Eventf(policy, corev1.EventTypeNormal, "ScaleDownLimited",
+					"ScaleDown of %s %s/%s limited to %d replicas (requested %d) by %s",
+					targetKind(workload.Object), workload.GetNamespace(), workload.GetName(), floor, newReplicas, reason)
+				newReplicas = floor
+			}
+			if newReplicas >= currentReplicas {
+				actionLog.Info("Skipping action: workload is already at or below the target replicas",
+					"current_replicas", currentReplicas,
+					"target_replicas", newReplicas,
+				)
+				continue
+			}
+
+			// Back up the workload so the original replicas can be restored
+			if _, err := r.snapshot(ctx, policy, action.Type, workload.Object, revertAfter, replicasPath); err != nil {
+				return applied, err
+			}
+
+			workload.setReplicas(newReplicas)
+			now := time.Now()
+			markRemediated(workload, now)
+			if revertAfter > 0 {
+				scheduleReversion(workload, policy, rule, action.ScalingParams, revertAfter, now)
+			}
+
+			actionLog.Info("Scaling down workload",
+				"original_replicas", currentReplicas,
+				"new_replicas", newReplicas,
+				"scaling_duration", action.ScalingParams.ScalingDuration,
+			)
+
+			if err := r.Update(ctx, workload.Object); err != nil {
+				actionLog.Error(err, "Failed to scale down workload")
+				return applied, fmt.Errorf("failed to scale down %s: %v", targetKind(workload.Object), err)
+			}
+			applied = true
+			if revertAfter > 0 {
+				trackPendingReversion(policy, targetKind(workload.Object), workload, now.Add(revertAfter))
+			}
+
+		case remediationv1alpha1.RestartPod:
+			restarted, err := r.restartPods(ctx, policy, action, target)
+			if err != nil {
+				actionLog.Error(err, "Failed to restart pods")
+				return applied, err
+			}
+			applied = applied || restarted
+
+		case remediationv1alpha1.RollbackDeployment:
+			if deployment == nil {
+				actionLog.Info("Skipping action: RollbackDeployment requires a Deployment target")
+				continue
+			}
+			rolledBack, err := r.rollbackDeployment(ctx, policy, action, deployment)
+			if err != nil {
+				actionLog.Error(err, "Failed to roll back deployment")
+				return applied, err
+			}
+			applied = applied || rolledBack
+
+		case remediationv1alpha1.UpdateResources:
+			updated, err := r.updateResources(ctx, policy, rule, action, target)
+			if err != nil {
+				actionLog.Error(err, "Failed to update resources")
+				return applied, err
+			}
+			applied = applied || updated
 
 		case remediationv1alpha1.AdjustHPALimits:
 			if action.ScalingParams == nil {
@@ -331,22 +727,21 @@ func (r *SelfRemediationPolicyReconciler) executeActions(
 				actionLog.Info("Skipping action: temporary max replicas not set")
 				continue
 			}
+			revertAfter, err := scalingDuration(action.ScalingParams)
+			if err != nil {
+				actionLog.Error(err, "Skipping action: invalid scaling duration")
+				continue
+			}
 
-			hpa, err := r.resolveHPAForAction(ctx, action, deployment)
+			hpa, err := r.resolveHPAForAction(ctx, action, target)
 			if err != nil {
-				return err
+				return applied, err
 			}
 			if hpa == nil {
 				actionLog.Info("Skipping action: no matching HPA found")
 				continue
 			}
 
-			if hpa.Annotations == nil {
-				hpa.Annotations = make(map[string]string)
-			}
-			// Store original maxReplicas for later reversion.
-			hpa.Annotations["kubemedic.io/original-hpa-max-replicas"] = fmt.Sprintf("%d", hpa.Spec.MaxReplicas)
-
 			newMax := *action.ScalingParams.TemporaryMaxReplicas
 			if newMax < 1 {
 				actionLog.Info("Skipping action: temporary max replicas must be >= 1")
@@ -358,6 +753,11 @@ func (r *SelfRemediationPolicyReconciler) executeActions(
 				newMax = *hpa.Spec.MinReplicas
 			}
 
+			// Back up the HPA so the original maxReplicas can be restored
+			if _, err := r.snapshot(ctx, policy, action.Type, hpa, revertAfter, maxReplicasPath); err != nil {
+				return applied, err
+			}
+
 			actionLog.Info("Adjusting HPA maxReplicas",
 				"hpa", types.NamespacedName{Namespace: hpa.Namespace, Name: hpa.Name}.String(),
 				"original_max_replicas", hpa.Spec.MaxReplicas,
@@ -366,47 +766,28 @@ func (r *SelfRemediationPolicyReconciler) executeActions(
 			)
 
 			hpa.Spec.MaxReplicas = newMax
+			now := time.Now()
+			markRemediated(hpa, now)
+			if revertAfter > 0 {
+				scheduleReversion(hpa, policy, rule, action.ScalingParams, revertAfter, now)
+			}
 			if err := r.Update(ctx, hpa); err != nil {
 				actionLog.Error(err, "Failed to update HPA")
-				return fmt.Errorf("failed to update HPA: %v", err)
+				return applied, fmt.Errorf("failed to update HPA: %v", err)
 			}
-
-			if action.ScalingParams.ScalingDuration != "" {
-				duration, _ := time.ParseDuration(action.ScalingParams.ScalingDuration)
-				go r.scheduleHPAReversion(hpa.Namespace, hpa.Name, duration)
+			applied = true
+			if revertAfter > 0 {
+				trackPendingReversion(policy, "HorizontalPodAutoscaler", hpa, now.Add(revertAfter))
 			}
 		}
 	}
-	return nil
-}
-
-func (r *SelfRemediationPolicyReconciler) scheduleReversion(deployment *appsv1.Deployment, duration time.Duration) {
-	time.Sleep(duration)
-
-	ctx := context.Background()
-	// Get the current deployment
-	var currentDeployment appsv1.Deployment
-	if err := r.Get(ctx, client.ObjectKey{
-		Namespace: deployment.Namespace,
-		Name:      deployment.Name,
-	}, &currentDeployment); err != nil {
-		return
-	}
-
-	// Get original replicas
-	if originalStr, ok := currentDeployment.Annotations["kubemedic.io/original-replicas"]; ok {
-		if original, err := strconv.ParseInt(originalStr, 10, 32); err == nil {
-			originalReplicas := int32(original)
-			currentDeployment.Spec.Replicas = &originalReplicas
-			_ = r.Update(ctx, &currentDeployment)
-		}
-	}
+	return applied, nil
 }
 
 func (r *SelfRemediationPolicyReconciler) resolveHPAForAction(
 	ctx context.Context,
 	action remediationv1alpha1.Action,
-	deployment *appsv1.Deployment,
+	workload client.Object,
 ) (*autoscalingv2.HorizontalPodAutoscaler, error) {
 	// If the action explicitly targets an HPA, use it.
 	if action.Target.Kind == "HorizontalPodAutoscaler" || action.Target.Kind == "HPA" {
@@ -417,15 +798,19 @@ func (r *SelfRemediationPolicyReconciler) resolveHPAForAction(
 		return &hpa, nil
 	}
 
-	// Default: locate the HPA that scales the given Deployment.
+	// Default: locate the HPA that scales the given workload.
+	if workload == nil {
+		return nil, nil
+	}
 	var hpas autoscalingv2.HorizontalPodAutoscalerList
-	if err := r.List(ctx, &hpas, client.InNamespace(deployment.Namespace)); err != nil {
+	if err := r.List(ctx, &hpas, client.InNamespace(workload.GetNamespace())); err != nil {
 		return nil, fmt.Errorf("failed to list HPAs: %w", err)
 	}
 
+	kind := targetKind(workload)
 	for i := range hpas.Items {
 		h := &hpas.Items[i]
-		if h.Spec.ScaleTargetRef.Kind == "Deployment" && h.Spec.ScaleTargetRef.Name == deployment.Name {
+		if h.Spec.ScaleTargetRef.Kind == kind && h.Spec.ScaleTargetRef.Name == workload.GetName() {
 			return h, nil
 		}
 	}
@@ -433,30 +818,10 @@ func (r *SelfRemediationPolicyReconciler) resolveHPAForAction(
 	return nil, nil
 }
 
-func (r *SelfRemediationPolicyReconciler) scheduleHPAReversion(namespace, name string, duration time.Duration) {
-	time.Sleep(duration)
-
-	ctx := context.Background()
-	var current autoscalingv2.HorizontalPodAutoscaler
-	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &current); err != nil {
-		return
-	}
-
-	if current.Annotations == nil {
-		return
-	}
-
-	if originalStr, ok := current.Annotations["kubemedic.io/original-hpa-max-replicas"]; ok {
-		if original, err := strconv.ParseInt(originalStr, 10, 32); err == nil {
-			current.Spec.MaxReplicas = int32(original)
-			_ = r.Update(ctx, &current)
-		}
-	}
-}
-
 // SetupWithManager sets up the controller with the Manager.
 func (r *SelfRemediationPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&remediationv1alpha1.SelfRemediationPolicy{}).
+		Watches(&remediationv1alpha1.KubeMedicConfig{}, handler.EnqueueRequestsFromMapFunc(r.policiesForConfig)).
 		Complete(r)
 }
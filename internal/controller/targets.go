@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// targetPods returns the live pods the policy monitors: the pod its targetRef names, the
+// pods of the Deployment, StatefulSet or DaemonSet it names, or the pods its selector
+// matches. Pods that have finished or are being deleted are left out, and a target that
+// does not exist has no pods.
+func (r *SelfRemediationPolicyReconciler) targetPods(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+) ([]corev1.Pod, error) {
+	ref := policy.Spec.TargetRef
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = policy.Namespace
+	}
+
+	var selector labels.Selector
+	switch {
+	case ref.Selector != nil:
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(ref.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target selector: %w", err)
+		}
+
+	case ref.Kind == "" || ref.Kind == "Pod":
+		var pod corev1.Pod
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &pod); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get Pod: %w", err)
+		}
+		if !isLivePod(&pod) {
+			return nil, nil
+		}
+		return []corev1.Pod{pod}, nil
+
+	default:
+		var err error
+		selector, err = r.workloadSelector(ctx, ref.Kind, types.NamespacedName{Namespace: namespace, Name: ref.Name})
+		if err != nil || selector == nil {
+			return nil, err
+		}
+	}
+
+	var list corev1.PodList
+	if err := r.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	pods := make([]corev1.Pod, 0, len(list.Items))
+	for _, pod := range list.Items {
+		if isLivePod(&pod) {
+			pods = append(pods, pod)
+		}
+	}
+	slices.SortFunc(pods, func(a, b corev1.Pod) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return pods, nil
+}
+
+// workloadSelector returns the pod selector of a Deployment, StatefulSet or DaemonSet, or
+// nil when the workload does not exist
+func (r *SelfRemediationPolicyReconciler) workloadSelector(
+	ctx context.Context,
+	kind string,
+	key types.NamespacedName,
+) (labels.Selector, error) {
+	var workload client.Object
+	switch kind {
+	case "Deployment":
+		workload = &appsv1.Deployment{}
+	case "StatefulSet":
+		workload = &appsv1.StatefulSet{}
+	case "DaemonSet":
+		workload = &appsv1.DaemonSet{}
+	default:
+		return nil, fmt.Errorf("unsupported target kind %q", kind)
+	}
+	if err := r.Get(ctx, key, workload); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s: %w", kind, err)
+	}
+
+	var podSelector *metav1.LabelSelector
+	switch workload := workload.(type) {
+	case *appsv1.Deployment:
+		podSelector = workload.Spec.Selector
+	case *appsv1.StatefulSet:
+		podSelector = workload.Spec.Selector
+	case *appsv1.DaemonSet:
+		podSelector = workload.Spec.Selector
+	}
+	selector, err := metav1.LabelSelectorAsSelector(podSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of %s %s: %w", kind, key, err)
+	}
+	return selector, nil
+}
+
+// isLivePod reports whether the pod is neither finished nor being deleted
+func isLivePod(pod *corev1.Pod) bool {
+	return pod.DeletionTimestamp.IsZero() &&
+		pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
+
+// remediationPod returns the pod that actions without a target apply to: the pod furthest
+// over the first of the rule's conditions
+func remediationPod(pods []corev1.Pod, results []ConditionResult) *corev1.Pod {
+	for _, result := range results {
+		for i := range pods {
+			if pods[i].Name == result.Pod {
+				return &pods[i]
+			}
+		}
+	}
+	if len(pods) > 0 {
+		return &pods[0]
+	}
+	return nil
+}
+
+// targetRefString describes the policy's targetRef for logs
+func targetRefString(ref remediationv1alpha1.TargetReference) string {
+	if ref.Selector != nil {
+		return fmt.Sprintf("pods in %s matching %s", ref.Namespace, metav1.FormatLabelSelector(ref.Selector))
+	}
+	kind := ref.Kind
+	if kind == "" {
+		kind = "Pod"
+	}
+	return fmt.Sprintf("%s %s/%s", kind, ref.Namespace, ref.Name)
+}
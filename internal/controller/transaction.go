@@ -0,0 +1,210 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// ruleTransaction collects the backups taken by the actions of one rule execution, so the
+// changes of the actions that succeeded can be undone when a later action fails
+type ruleTransaction struct {
+	// committed holds the backups of actions that were applied, in order
+	committed []*remediationv1alpha1.RemediationBackup
+	// pending holds the backups taken by the action currently executing
+	pending []*remediationv1alpha1.RemediationBackup
+}
+
+type ruleTransactionKey struct{}
+
+// withRuleTransaction returns a context in which every backup taken is recorded in the
+// returned transaction
+func withRuleTransaction(ctx context.Context) (context.Context, *ruleTransaction) {
+	tx := &ruleTransaction{}
+	return context.WithValue(ctx, ruleTransactionKey{}, tx), tx
+}
+
+// recordBackup adds a backup to the transaction of the context, if any
+func recordBackup(ctx context.Context, backup *remediationv1alpha1.RemediationBackup) {
+	if tx, ok := ctx.Value(ruleTransactionKey{}).(*ruleTransaction); ok {
+		tx.pending = append(tx.pending, backup)
+	}
+}
+
+// commit marks the backups of the current action as belonging to an applied change and
+// returns them
+func (tx *ruleTransaction) commit() []*remediationv1alpha1.RemediationBackup {
+	backups := tx.pending
+	tx.committed = append(tx.committed, backups...)
+	tx.pending = nil
+	return backups
+}
+
+// discard forgets the backups of an action that made no change
+func (tx *ruleTransaction) discard() {
+	tx.pending = nil
+}
+
+// rollbackRule undoes the changes of the actions of a rule after one of its actions failed,
+// including what the failing action changed before it failed, and records the failure in
+// the policy status and as Events. Each changed resource is restored from its earliest
+// backup in the rule; fields changed by others since are left alone and reported instead.
+// Evicted pods and rolling restarts cannot be undone and are reported as not restorable.
+// The cooldowns and rate limit tokens of the rolled back actions are kept: the actions
+// did disturb their targets, which is what those limits guard against.
+func (r *SelfRemediationPolicyReconciler) rollbackRule(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	rule string,
+	failed remediationv1alpha1.ActionType,
+	tx *ruleTransaction,
+	cause error,
+) {
+	log := log.FromContext(ctx).WithValues("rule", rule)
+	failure := &remediationv1alpha1.RemediationFailure{
+		Rule:    rule,
+		Action:  failed,
+		Message: cause.Error(),
+		Time:    metav1.NewTime(time.Now()),
+	}
+	r.Recorder.Eventf(policy, corev1.EventTypeWarning, "RemediationFailed",
+		"%s of rule %s failed: %v", failed, rule, cause)
+
+	// Restore each resource from its first backup, allowing every field changed by the rule
+	type change struct {
+		backup *remediationv1alpha1.RemediationBackup
+		paths  []string
+		// pending is set when only the failing action backed up the resource
+		pending bool
+		// restartOnly is set when the resource was only restarted
+		restartOnly bool
+	}
+	var order []string
+	changes := make(map[string]*change)
+	add := func(backup *remediationv1alpha1.RemediationBackup, pending bool) {
+		ref := backup.Spec.ResourceRef
+		key := fmt.Sprintf("%s/%s/%s/%s", ref.APIGroup, ref.Kind, ref.Namespace, ref.Name)
+		restart := backup.Spec.ActionType == string(remediationv1alpha1.RestartPod)
+		if existing, ok := changes[key]; ok {
+			existing.paths = append(existing.paths, backup.Spec.ModifiedPaths...)
+			existing.pending = existing.pending && pending
+			existing.restartOnly = existing.restartOnly && restart
+			return
+		}
+		order = append(order, key)
+		changes[key] = &change{
+			backup:      backup,
+			paths:       append([]string(nil), backup.Spec.ModifiedPaths...),
+			pending:     pending,
+			restartOnly: restart,
+		}
+	}
+	for _, backup := range tx.committed {
+		add(backup, false)
+	}
+	for _, backup := range tx.pending {
+		add(backup, true)
+	}
+
+	// Undo the most recent change first
+	for i := len(order) - 1; i >= 0; i-- {
+		change := changes[order[i]]
+		ref := change.backup.Spec.ResourceRef
+		resource := fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+
+		// The failing action may have stopped before changing the resource
+		if change.pending {
+			unchanged, err := backupUnchanged(ctx, r.Client, change.backup)
+			if err != nil {
+				log.Error(err, "Failed to compare resource with its backup", "backup", change.backup.Name)
+			}
+			if unchanged {
+				continue
+			}
+		}
+
+		if change.restartOnly {
+			reason := "rolling restart cannot be undone"
+			if ref.Kind == "Pod" {
+				reason = "evicted pod cannot be restored"
+			}
+			log.Info("Not restoring backup of a restart", "backup", change.backup.Name, "resource", resource)
+			failure.RollbackErrors = append(failure.RollbackErrors, fmt.Sprintf("%s: %s", resource, reason))
+			r.Recorder.Eventf(policy, corev1.EventTypeWarning, "RevertFailed",
+				"Did not restore %s from backup %s: %s", resource, change.backup.Name, reason)
+			continue
+		}
+
+		resource, conflicts, applied, err := restoreBackup(ctx, r.Client, change.backup, change.paths, false)
+		switch {
+		case err != nil:
+			log.Error(err, "Failed to restore backup", "backup", change.backup.Name)
+			failure.RollbackErrors = append(failure.RollbackErrors, fmt.Sprintf("%s: %v", resource, err))
+			r.Recorder.Eventf(policy, corev1.EventTypeWarning, "RevertFailed",
+				"Could not restore %s from backup %s: %v", resource, change.backup.Name, err)
+		case !applied:
+			log.Info("Not restoring backup of a resource changed by others",
+				"backup", change.backup.Name, "conflicts", conflicts)
+			failure.RollbackErrors = append(failure.RollbackErrors,
+				fmt.Sprintf("%s: changed by others since backup %s (%d fields)", resource, change.backup.Name, len(conflicts)))
+			r.Recorder.Eventf(policy, corev1.EventTypeWarning, "RevertFailed",
+				"Did not restore %s from backup %s: %d fields were changed by others",
+				resource, change.backup.Name, len(conflicts))
+		default:
+			log.Info("Restored backup after failed action", "backup", change.backup.Name, "resource", resource)
+			failure.RolledBack = append(failure.RolledBack, resource)
+			r.Recorder.Eventf(policy, corev1.EventTypeNormal, "ActionReverted",
+				"Restored %s from backup %s after %s of rule %s failed", resource, change.backup.Name, failed, rule)
+		}
+	}
+
+	policy.Status.LastFailure = failure
+}
+
+// backupUnchanged reports whether the resource still matches the state saved in the backup
+func backupUnchanged(
+	ctx context.Context,
+	c client.Client,
+	backup *remediationv1alpha1.RemediationBackup,
+) (bool, error) {
+	var original unstructured.Unstructured
+	if err := original.UnmarshalJSON(backup.Spec.OriginalState.Raw); err != nil {
+		return false, fmt.Errorf("failed to decode original state: %w", err)
+	}
+	ref := backup.Spec.ResourceRef
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(original.GroupVersionKind())
+	if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, current); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get %s %s/%s: %w", ref.Kind, ref.Namespace, ref.Name, err)
+	}
+	return current.GetUID() == original.GetUID() && len(changedFields(backup, &original, current)) == 0, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+func TestRollbackRule(t *testing.T) {
+	policy := &remediationv1alpha1.SelfRemediationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "scale", Namespace: "shop"},
+	}
+	deployment := func(name string, replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", UID: types.UID(name + "-uid")},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+	}
+	// scaled returns a backup of the deployment with the replicas it had before being scaled
+	scaled := func(name string, replicas int32) *remediationv1alpha1.RemediationBackup {
+		backup := testBackup(t, policy, deployment(name, replicas), replicasPath)
+		backup.Name = fmt.Sprintf("%s-%d", name, replicas)
+		backup.Spec.ActionType = string(remediationv1alpha1.ScaleUp)
+		return backup
+	}
+	evicted := func(name string) *remediationv1alpha1.RemediationBackup {
+		pod := &corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		}
+		backup := testBackup(t, policy, pod)
+		backup.Name = name
+		backup.Spec.ActionType = string(remediationv1alpha1.RestartPod)
+		return backup
+	}
+
+	tests := []struct {
+		name           string
+		committed      []*remediationv1alpha1.RemediationBackup
+		pending        []*remediationv1alpha1.RemediationBackup
+		wantRolledBack []string
+		wantErrors     []string
+		wantReplicas   map[string]int32
+	}{
+		{
+			name:           "most recent change undone first",
+			committed:      []*remediationv1alpha1.RemediationBackup{scaled("checkout", 2), scaled("cart", 3)},
+			wantRolledBack: []string{"Deployment shop/cart", "Deployment shop/checkout"},
+			wantReplicas:   map[string]int32{"checkout": 2, "cart": 3},
+		},
+		{
+			name:           "restored from the earliest backup",
+			committed:      []*remediationv1alpha1.RemediationBackup{scaled("checkout", 2), scaled("cart", 3), scaled("checkout", 4)},
+			wantRolledBack: []string{"Deployment shop/cart", "Deployment shop/checkout"},
+			wantReplicas:   map[string]int32{"checkout": 2, "cart": 3},
+		},
+		{
+			name:           "change of the failing action undone",
+			committed:      []*remediationv1alpha1.RemediationBackup{scaled("checkout", 2)},
+			pending:        []*remediationv1alpha1.RemediationBackup{scaled("cart", 3)},
+			wantRolledBack: []string{"Deployment shop/cart", "Deployment shop/checkout"},
+			wantReplicas:   map[string]int32{"checkout": 2, "cart": 3},
+		},
+		{
+			name:           "failing action stopped before its change",
+			committed:      []*remediationv1alpha1.RemediationBackup{scaled("checkout", 2)},
+			pending:        []*remediationv1alpha1.RemediationBackup{scaled("web", 6)},
+			wantRolledBack: []string{"Deployment shop/checkout"},
+			wantReplicas:   map[string]int32{"checkout": 2, "web": 6},
+		},
+		{
+			name:           "evicted pod reported",
+			committed:      []*remediationv1alpha1.RemediationBackup{scaled("checkout", 2), evicted("checkout-0")},
+			wantRolledBack: []string{"Deployment shop/checkout"},
+			wantErrors:     []string{"Pod shop/checkout-0: evicted pod cannot be restored"},
+			wantReplicas:   map[string]int32{"checkout": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every deployment was scaled to six replicas
+			c := newTestClient(t, deployment("checkout", 6), deployment("cart", 6), deployment("web", 6))
+			r := &SelfRemediationPolicyReconciler{Client: c, Recorder: record.NewFakeRecorder(20)}
+			policy := policy.DeepCopy()
+			tx := &ruleTransaction{committed: tt.committed, pending: tt.pending}
+
+			r.rollbackRule(context.Background(), policy, "cpu", remediationv1alpha1.ScaleUp, tx, goerrors.New("quota exceeded"))
+
+			failure := policy.Status.LastFailure
+			if failure == nil {
+				t.Fatal("no failure recorded")
+			}
+			if !slices.Equal(failure.RolledBack, tt.wantRolledBack) {
+				t.Errorf("RolledBack = %v, want %v", failure.RolledBack, tt.wantRolledBack)
+			}
+			if !slices.Equal(failure.RollbackErrors, tt.wantErrors) {
+				t.Errorf("RollbackErrors = %v, want %v", failure.RollbackErrors, tt.wantErrors)
+			}
+			for name, want := range tt.wantReplicas {
+				var current appsv1.Deployment
+				if err := c.Get(context.Background(), client.ObjectKey{Namespace: "shop", Name: name}, &current); err != nil {
+					t.Fatal(err)
+				}
+				if got := replicasOrDefault(current.Spec.Replicas); got != want {
+					t.Errorf("deployment %s has %d replicas, want %d", name, got, want)
+				}
+			}
+		})
+	}
+}
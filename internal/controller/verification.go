@@ -0,0 +1,276 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// maxVerificationHistory is how many completed verifications are kept in the policy status
+const maxVerificationHistory = 10
+
+// parseImprovement parses VerificationParameters.MinImprovement ("20%" or "20")
+func parseImprovement(raw string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(raw), "%")), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid min improvement %q: %w", raw, err)
+	}
+	return value, nil
+}
+
+// conditionSamples records the observed value of each condition
+func conditionSamples(results []ConditionResult) []remediationv1alpha1.ConditionSample {
+	samples := make([]remediationv1alpha1.ConditionSample, 0, len(results))
+	for _, result := range results {
+		samples = append(samples, remediationv1alpha1.ConditionSample{
+			Type:  result.Condition.Type,
+			Value: formatSample(result.Observed),
+		})
+	}
+	return samples
+}
+
+// formatSample formats a measured value for the policy status
+func formatSample(value float64) string {
+	if value == math.Trunc(value) {
+		return strconv.FormatFloat(value, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(value, 'f', 3, 64)
+}
+
+// startVerification records that the effect of an applied action is to be checked within
+// the action's verification window
+func startVerification(
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	rule string,
+	action remediationv1alpha1.Action,
+	target client.Object,
+	backups []*remediationv1alpha1.RemediationBackup,
+	results []ConditionResult,
+	now time.Time,
+) error {
+	window, err := time.ParseDuration(action.Verification.Window)
+	if err != nil {
+		return fmt.Errorf("invalid verification window %q: %w", action.Verification.Window, err)
+	}
+
+	verification := remediationv1alpha1.RemediationVerification{
+		Rule:      rule,
+		Action:    action.Type,
+		Kind:      targetKind(target),
+		Name:      target.GetName(),
+		Namespace: target.GetNamespace(),
+		StartTime: metav1.NewTime(now),
+		Deadline:  metav1.NewTime(now.Add(window)),
+		Baseline:  conditionSamples(results),
+		Result:    remediationv1alpha1.VerificationPending,
+	}
+	if len(backups) > 0 {
+		verification.Backup = backups[0].Name
+	}
+	policy.Status.Verifications = append(policy.Status.Verifications, verification)
+	return nil
+}
+
+// verificationParams returns the verification settings of the action a verification was
+// started for, as currently configured on the policy
+func verificationParams(
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	verification *remediationv1alpha1.RemediationVerification,
+) *remediationv1alpha1.VerificationParameters {
+	for _, rule := range policy.Spec.Rules {
+		if rule.Name != verification.Rule {
+			continue
+		}
+		for _, action := range rule.Actions {
+			if action.Type == verification.Action && action.Verification != nil {
+				return action.Verification
+			}
+		}
+	}
+	return &remediationv1alpha1.VerificationParameters{}
+}
+
+// improvement returns the smallest relative improvement of any condition from its baseline,
+// as a percentage. Conditions without a positive baseline count as not improved.
+func improvement(baseline []remediationv1alpha1.ConditionSample, results []ConditionResult) float64 {
+	if len(results) == 0 || len(baseline) != len(results) {
+		return 0
+	}
+	smallest := math.Inf(1)
+	for i, result := range results {
+		if baseline[i].Type != result.Condition.Type {
+			return 0
+		}
+		before, err := strconv.ParseFloat(baseline[i].Value, 64)
+		if err != nil || before <= 0 {
+			return 0
+		}
+		change := (before - result.Observed) / before * 100
+		// Conditions on values falling too low improve as the value rises
+		if result.Threshold.Below() && result.Condition.Aggregation != remediationv1alpha1.AggregateCountOverThreshold {
+			change = -change
+		}
+		smallest = math.Min(smallest, change)
+	}
+	return smallest
+}
+
+// verifyRemediations checks the pending verifications against the condition results of
+// their rules measured at evaluatedAt; verifications started since are left for the next
+// check. A remediation is effective once its rule's conditions clear or improve by the
+// required amount; one still pending at its deadline is ineffective and, unless disabled,
+// restored from its backup. It returns the time until the next deadline.
+func (r *SelfRemediationPolicyReconciler) verifyRemediations(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	ruleResults map[string][]ConditionResult,
+	evaluatedAt time.Time,
+) time.Duration {
+	log := log.FromContext(ctx)
+	now := time.Now()
+	var next time.Duration
+
+	for i := range policy.Status.Verifications {
+		verification := &policy.Status.Verifications[i]
+		if verification.Result != remediationv1alpha1.VerificationPending {
+			continue
+		}
+		params := verificationParams(policy, verification)
+		target := fmt.Sprintf("%s %s/%s", verification.Kind, verification.Namespace, verification.Name)
+
+		// Results measured before the action was taken say nothing about its effect
+		results := ruleResults[verification.Rule]
+		if len(results) > 0 && !verification.StartTime.After(evaluatedAt) {
+			verification.Observed = conditionSamples(results)
+			improved := improvement(verification.Baseline, results)
+			verification.Improvement = formatSample(improved) + "%"
+
+			cleared := true
+			for _, result := range results {
+				if result.Met {
+					cleared = false
+				}
+			}
+			required, err := parseImprovement(params.MinImprovement)
+			if cleared || (params.MinImprovement != "" && err == nil && improved >= required) {
+				verification.Result = remediationv1alpha1.VerificationEffective
+				verification.Message = fmt.Sprintf("conditions improved by %s", verification.Improvement)
+				if cleared {
+					verification.Message = "conditions cleared"
+				}
+				verification.CompletionTime = &metav1.Time{Time: now}
+				policy.Status.EffectiveRemediations++
+				log.Info("Remediation was effective", "action", verification.Action, "target", target)
+				r.Recorder.Eventf(policy, corev1.EventTypeNormal, "RemediationEffective",
+					"%s on %s of rule %s was effective: %s",
+					verification.Action, target, verification.Rule, verification.Message)
+				continue
+			}
+		}
+
+		if remaining := verification.Deadline.Sub(now); remaining > 0 {
+			if next == 0 || remaining < next {
+				next = remaining
+			}
+			continue
+		}
+
+		verification.CompletionTime = &metav1.Time{Time: now}
+		if len(verification.Observed) == 0 {
+			verification.Result = remediationv1alpha1.VerificationInconclusive
+			verification.Message = "conditions could not be measured within the window"
+			continue
+		}
+		verification.Result = remediationv1alpha1.VerificationIneffective
+		verification.Message = fmt.Sprintf("conditions improved by %s within %s",
+			verification.Improvement, verification.Deadline.Sub(verification.StartTime.Time))
+		policy.Status.IneffectiveRemediations++
+		if params.RevertIfIneffective == nil || *params.RevertIfIneffective {
+			verification.Reverted, verification.Message = r.revertIneffective(ctx, policy, verification)
+		}
+		log.Info("Remediation was ineffective", "action", verification.Action, "target", target,
+			"reverted", verification.Reverted)
+		r.Recorder.Eventf(policy, corev1.EventTypeWarning, "RemediationIneffective",
+			"%s on %s of rule %s was ineffective: %s",
+			verification.Action, target, verification.Rule, verification.Message)
+	}
+
+	pruneVerifications(policy)
+	return next
+}
+
+// revertIneffective restores the target of an ineffective remediation from its backup and
+// returns whether it was restored and the verification message
+func (r *SelfRemediationPolicyReconciler) revertIneffective(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	verification *remediationv1alpha1.RemediationVerification,
+) (bool, string) {
+	message := verification.Message
+	if verification.Backup == "" {
+		return false, message + "; no backup to revert from"
+	}
+
+	var backup remediationv1alpha1.RemediationBackup
+	if err := r.Get(ctx, types.NamespacedName{Namespace: policy.Namespace, Name: verification.Backup}, &backup); err != nil {
+		return false, fmt.Sprintf("%s; not reverted: %v", message, err)
+	}
+	_, conflicts, applied, err := restoreBackup(ctx, r.Client, &backup, backup.Spec.ModifiedPaths, false)
+	switch {
+	case err != nil:
+		log.FromContext(ctx).Error(err, "Failed to revert ineffective remediation", "backup", backup.Name)
+		return false, fmt.Sprintf("%s; not reverted: %v", message, err)
+	case !applied:
+		return false, fmt.Sprintf("%s; not reverted: %d fields were changed by others", message, len(conflicts))
+	default:
+		return true, fmt.Sprintf("%s; reverted from backup %s", message, backup.Name)
+	}
+}
+
+// pruneVerifications keeps the pending verifications and the most recent completed ones
+func pruneVerifications(policy *remediationv1alpha1.SelfRemediationPolicy) {
+	completed := 0
+	for _, verification := range policy.Status.Verifications {
+		if verification.Result != remediationv1alpha1.VerificationPending {
+			completed++
+		}
+	}
+
+	kept := policy.Status.Verifications[:0]
+	for _, verification := range policy.Status.Verifications {
+		if verification.Result != remediationv1alpha1.VerificationPending && completed > maxVerificationHistory {
+			completed--
+			continue
+		}
+		kept = append(kept, verification)
+	}
+	policy.Status.Verifications = kept
+}
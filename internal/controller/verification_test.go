@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"math"
+	"testing"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+	"github.com/ikepcampbell/kubemedic/pkg/threshold"
+)
+
+func TestImprovement(t *testing.T) {
+	cpu := remediationv1alpha1.CPUUsage
+	memory := remediationv1alpha1.MemoryUsage
+	type sample struct {
+		conditionType remediationv1alpha1.ConditionType
+		threshold     string
+		aggregation   string
+		before        string
+		after         float64
+	}
+
+	tests := []struct {
+		name    string
+		samples []sample
+		want    float64
+	}{
+		{name: "no conditions"},
+		{name: "value halved", samples: []sample{{conditionType: cpu, threshold: ">=80", before: "90", after: 45}}, want: 50},
+		{name: "value rose", samples: []sample{{conditionType: cpu, threshold: ">=80", before: "80", after: 100}}, want: -25},
+		{name: "value falling too low rose", samples: []sample{{conditionType: cpu, threshold: "<=10", before: "8", after: 10}}, want: 25},
+		{
+			name:    "count of pods below the threshold fell",
+			samples: []sample{{conditionType: cpu, threshold: "<=10", aggregation: remediationv1alpha1.AggregateCountOverThreshold, before: "4", after: 1}},
+			want:    75,
+		},
+		{
+			name: "smallest improvement of several conditions",
+			samples: []sample{
+				{conditionType: cpu, threshold: ">=80", before: "90", after: 45},
+				{conditionType: memory, threshold: ">=80", before: "100", after: 90},
+			},
+			want: 10,
+		},
+		{name: "zero baseline", samples: []sample{{conditionType: cpu, threshold: ">=80", before: "0", after: 0}}},
+		{name: "unreadable baseline", samples: []sample{{conditionType: cpu, threshold: ">=80", before: "high", after: 45}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var baseline []remediationv1alpha1.ConditionSample
+			var results []ConditionResult
+			for _, s := range tt.samples {
+				parsed, err := threshold.Parse(s.threshold, threshold.Number)
+				if err != nil {
+					t.Fatal(err)
+				}
+				condition := remediationv1alpha1.Condition{Type: s.conditionType, Threshold: s.threshold, Aggregation: s.aggregation}
+				baseline = append(baseline, remediationv1alpha1.ConditionSample{Type: s.conditionType, Value: s.before})
+				results = append(results, ConditionResult{Condition: condition, Threshold: parsed, Observed: s.after})
+			}
+			if got := improvement(baseline, results); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("improvement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Results that do not line up with the baseline, after the rule changed, are not compared
+	cpuResult := ConditionResult{Condition: remediationv1alpha1.Condition{Type: cpu}, Observed: 10}
+	baseline := []remediationv1alpha1.ConditionSample{{Type: memory, Value: "90"}}
+	if got := improvement(baseline, []ConditionResult{cpuResult}); got != 0 {
+		t.Errorf("improvement() of another condition = %v, want 0", got)
+	}
+	if got := improvement(baseline, []ConditionResult{cpuResult, cpuResult}); got != 0 {
+		t.Errorf("improvement() of more conditions than the baseline = %v, want 0", got)
+	}
+}
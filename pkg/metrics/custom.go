@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	custommetrics "k8s.io/metrics/pkg/client/custom_metrics"
+	externalmetrics "k8s.io/metrics/pkg/client/external_metrics"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// CustomMetrics reads per-pod metrics from the custom.metrics.k8s.io API, served by
+// adapters such as prometheus-adapter
+type CustomMetrics struct {
+	client custommetrics.CustomMetricsClient
+}
+
+// NewCustomMetrics creates a source reading from the custom metrics API
+func NewCustomMetrics(client custommetrics.CustomMetricsClient) *CustomMetrics {
+	if client == nil {
+		panic("custom metrics client cannot be nil")
+	}
+	return &CustomMetrics{client: client}
+}
+
+// Name returns Custom
+func (c *CustomMetrics) Name() string {
+	return remediationv1alpha1.SourceCustom
+}
+
+// PodValue returns the value of the condition's metric for the pod
+func (c *CustomMetrics) PodValue(
+	_ context.Context,
+	pod *corev1.Pod,
+	condition remediationv1alpha1.Condition,
+) (float64, error) {
+	if pod == nil {
+		return 0, fmt.Errorf("pod is nil")
+	}
+	value, err := c.client.NamespacedMetrics(pod.Namespace).
+		GetForObject(schema.GroupKind{Kind: "Pod"}, pod.Name, condition.Metric, labels.Everything())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get custom metric %s: %w", condition.Metric, err)
+	}
+	return value.Value.AsApproximateFloat64(), nil
+}
+
+// ExternalMetrics reads metrics of systems outside the cluster, such as queue lengths,
+// from the external.metrics.k8s.io API
+type ExternalMetrics struct {
+	client externalmetrics.ExternalMetricsClient
+}
+
+// NewExternalMetrics creates a source reading from the external metrics API
+func NewExternalMetrics(client externalmetrics.ExternalMetricsClient) *ExternalMetrics {
+	if client == nil {
+		panic("external metrics client cannot be nil")
+	}
+	return &ExternalMetrics{client: client}
+}
+
+// Name returns External
+func (e *ExternalMetrics) Name() string {
+	return remediationv1alpha1.SourceExternal
+}
+
+// PodValue returns the sum of the series of the condition's metric in the pod's namespace
+// that match its metric selector. External metrics do not belong to pods, so every pod
+// of the target sees the same value.
+func (e *ExternalMetrics) PodValue(
+	_ context.Context,
+	pod *corev1.Pod,
+	condition remediationv1alpha1.Condition,
+) (float64, error) {
+	if pod == nil {
+		return 0, fmt.Errorf("pod is nil")
+	}
+	selector := labels.Everything()
+	if condition.MetricSelector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(condition.MetricSelector)
+		if err != nil {
+			return 0, fmt.Errorf("invalid metric selector: %w", err)
+		}
+	}
+
+	values, err := e.client.NamespacedMetrics(pod.Namespace).List(condition.Metric, selector)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get external metric %s: %w", condition.Metric, err)
+	}
+	if len(values.Items) == 0 {
+		return 0, fmt.Errorf("external metric %s has no data", condition.Metric)
+	}
+	var total float64
+	for _, value := range values.Items {
+		total += value.Value.AsApproximateFloat64()
+	}
+	return total, nil
+}
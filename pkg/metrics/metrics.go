@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics provides the sources the controller measures policy conditions with:
+// metrics-server, the Prometheus HTTP API and the custom and external metrics APIs.
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// MetricsSource measures the metric of a condition for a pod
+type MetricsSource interface {
+	// Name is the name conditions select the source by, such as Prometheus
+	Name() string
+	// PodValue returns the value of the condition's metric for the pod
+	PodValue(ctx context.Context, pod *corev1.Pod, condition remediationv1alpha1.Condition) (float64, error)
+}
+
+// ResolveSource returns the name of the source that measures the condition, or an empty
+// name for conditions read from the pod's status. It returns an error when the condition
+// names a source that cannot measure it or leaves out what the source needs.
+func ResolveSource(condition remediationv1alpha1.Condition) (string, error) {
+	source := condition.Source
+	if condition.Container != "" {
+		resourceUsage := condition.Type == remediationv1alpha1.CPUUsage || condition.Type == remediationv1alpha1.MemoryUsage
+		if !resourceUsage || (source != "" && source != remediationv1alpha1.SourceMetricsServer) {
+			return "", fmt.Errorf("container only applies to %s and %s conditions measured by %s",
+				remediationv1alpha1.CPUUsage, remediationv1alpha1.MemoryUsage, remediationv1alpha1.SourceMetricsServer)
+		}
+	}
+
+	switch condition.Type {
+	case remediationv1alpha1.PodRestarts, remediationv1alpha1.CrashLoopBackOff,
+		remediationv1alpha1.OOMKilled, remediationv1alpha1.ImagePullBackOff:
+		if source != "" {
+			return "", fmt.Errorf("%s conditions are read from the pod status and take no source", condition.Type)
+		}
+		return "", nil
+	case remediationv1alpha1.CPUUsage, remediationv1alpha1.MemoryUsage:
+		if source == "" {
+			source = remediationv1alpha1.SourceMetricsServer
+		}
+	case remediationv1alpha1.PromQL:
+		if source == "" {
+			source = remediationv1alpha1.SourcePrometheus
+		}
+		if source != remediationv1alpha1.SourcePrometheus {
+			return "", fmt.Errorf("%s conditions require the %s source", condition.Type, remediationv1alpha1.SourcePrometheus)
+		}
+	default:
+		if source == "" {
+			return "", fmt.Errorf("%s conditions require a source: %s, %s or %s", condition.Type,
+				remediationv1alpha1.SourcePrometheus, remediationv1alpha1.SourceCustom, remediationv1alpha1.SourceExternal)
+		}
+	}
+
+	switch source {
+	case remediationv1alpha1.SourceMetricsServer:
+		if condition.Type != remediationv1alpha1.CPUUsage && condition.Type != remediationv1alpha1.MemoryUsage {
+			return "", fmt.Errorf("%s only provides %s and %s", source,
+				remediationv1alpha1.CPUUsage, remediationv1alpha1.MemoryUsage)
+		}
+	case remediationv1alpha1.SourcePrometheus:
+		if condition.Query == "" {
+			return "", fmt.Errorf("%s conditions require a query", source)
+		}
+		if _, err := parseQuery(condition.Query); err != nil {
+			return "", err
+		}
+	case remediationv1alpha1.SourceCustom, remediationv1alpha1.SourceExternal:
+		if condition.Metric == "" {
+			return "", fmt.Errorf("%s conditions require a metric", source)
+		}
+	default:
+		return "", fmt.Errorf("unknown metrics source %q", source)
+	}
+	return source, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/metrics/pkg/client/clientset/versioned"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// MetricsServer reads CPU and memory usage from the metrics.k8s.io API
+type MetricsServer struct {
+	client versioned.Interface
+}
+
+// NewMetricsServer creates a source reading from the metrics.k8s.io API
+func NewMetricsServer(client versioned.Interface) *MetricsServer {
+	if client == nil {
+		panic("metrics client cannot be nil")
+	}
+	return &MetricsServer{client: client}
+}
+
+// Name returns MetricsServer
+func (s *MetricsServer) Name() string {
+	return remediationv1alpha1.SourceMetricsServer
+}
+
+// PodValue returns the CPU usage in cores or memory working set in bytes of the
+// condition's container, or summed over all containers when it names none
+func (s *MetricsServer) PodValue(
+	ctx context.Context,
+	pod *corev1.Pod,
+	condition remediationv1alpha1.Condition,
+) (float64, error) {
+	var resourceName corev1.ResourceName
+	switch condition.Type {
+	case remediationv1alpha1.CPUUsage:
+		resourceName = corev1.ResourceCPU
+	case remediationv1alpha1.MemoryUsage:
+		resourceName = corev1.ResourceMemory
+	default:
+		return 0, fmt.Errorf("%s does not provide %s", s.Name(), condition.Type)
+	}
+
+	usage, err := s.ContainerUsage(ctx, pod, resourceName)
+	if err != nil {
+		return 0, err
+	}
+	if condition.Container != "" {
+		value, ok := usage[condition.Container]
+		if !ok {
+			return 0, fmt.Errorf("no metrics for container %s", condition.Container)
+		}
+		return value, nil
+	}
+	var total float64
+	for _, value := range usage {
+		total += value
+	}
+	return total, nil
+}
+
+// ContainerUsage returns the usage of the resource by each of the pod's containers, by
+// container name: CPU in cores and memory as the working set in bytes
+func (s *MetricsServer) ContainerUsage(
+	ctx context.Context,
+	pod *corev1.Pod,
+	resourceName corev1.ResourceName,
+) (map[string]float64, error) {
+	if pod == nil {
+		return nil, fmt.Errorf("pod is nil")
+	}
+	metrics, err := s.client.MetricsV1beta1().PodMetricses(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod metrics: %w", err)
+	}
+	if metrics == nil || len(metrics.Containers) == 0 {
+		return nil, fmt.Errorf("no metrics data available")
+	}
+
+	usage := make(map[string]float64, len(metrics.Containers))
+	for _, container := range metrics.Containers {
+		quantity, ok := container.Usage[resourceName]
+		if !ok {
+			continue
+		}
+		if resourceName == corev1.ResourceCPU {
+			usage[container.Name] = float64(quantity.MilliValue()) / 1000.0
+		} else {
+			usage[container.Name] = float64(quantity.Value())
+		}
+	}
+	return usage, nil
+}
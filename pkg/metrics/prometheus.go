@@ -0,0 +1,195 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+const (
+	// prometheusTimeout bounds queries when no HTTP client is given
+	prometheusTimeout = 10 * time.Second
+	// maxResponseSize bounds the query responses read
+	maxResponseSize = 1 << 20
+)
+
+// queryData is what query templates are executed with
+type queryData struct {
+	Namespace string
+	Pod       string
+}
+
+// Prometheus evaluates the PromQL queries of conditions with the Prometheus HTTP API
+type Prometheus struct {
+	address *url.URL
+	client  *http.Client
+}
+
+// NewPrometheus creates a source querying the Prometheus server at the address, such as
+// http://prometheus.monitoring:9090. A client with a timeout is used when client is nil.
+func NewPrometheus(address string, client *http.Client) (*Prometheus, error) {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Prometheus address %q: %w", address, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid Prometheus address %q: scheme must be http or https", address)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: prometheusTimeout}
+	}
+	return &Prometheus{address: parsed, client: client}, nil
+}
+
+// Name returns Prometheus
+func (p *Prometheus) Name() string {
+	return remediationv1alpha1.SourcePrometheus
+}
+
+// PodValue runs the condition's query for the pod and returns its single value
+func (p *Prometheus) PodValue(
+	ctx context.Context,
+	pod *corev1.Pod,
+	condition remediationv1alpha1.Condition,
+) (float64, error) {
+	if pod == nil {
+		return 0, fmt.Errorf("pod is nil")
+	}
+	query, err := RenderQuery(condition.Query, pod)
+	if err != nil {
+		return 0, err
+	}
+
+	endpoint := p.address.JoinPath("api", "v1", "query")
+	endpoint.RawQuery = url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create Prometheus request: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query Prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body prometheusResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode Prometheus response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if body.Status != "success" {
+		return 0, fmt.Errorf("prometheus query %q failed: %s: %s", query, body.ErrorType, body.Error)
+	}
+	return body.Data.value(query)
+}
+
+// RenderQuery executes a query template with the pod's namespace and name
+func RenderQuery(query string, pod *corev1.Pod) (string, error) {
+	tmpl, err := parseQuery(query)
+	if err != nil {
+		return "", err
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, queryData{Namespace: pod.Namespace, Pod: pod.Name}); err != nil {
+		return "", fmt.Errorf("failed to render query: %w", err)
+	}
+	return rendered.String(), nil
+}
+
+// parseQuery parses a query template, rejecting fields other than Namespace and Pod
+func parseQuery(query string) (*template.Template, error) {
+	tmpl, err := template.New("query").Option("missingkey=error").Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query template: %w", err)
+	}
+	// Rendering with placeholder values catches unknown fields before the policy is used
+	if err := tmpl.Execute(io.Discard, queryData{}); err != nil {
+		return nil, fmt.Errorf("invalid query template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// prometheusResponse is the envelope of Prometheus HTTP API responses
+type prometheusResponse struct {
+	Status    string         `json:"status"`
+	ErrorType string         `json:"errorType"`
+	Error     string         `json:"error"`
+	Data      prometheusData `json:"data"`
+}
+
+// prometheusData is the result of an instant query
+type prometheusData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// prometheusSample is a [timestamp, "value"] pair
+type prometheusSample [2]any
+
+// value returns the single value of a scalar result or of a vector with one series
+func (d prometheusData) value(query string) (float64, error) {
+	var sample prometheusSample
+	switch d.ResultType {
+	case "scalar":
+		if err := json.Unmarshal(d.Result, &sample); err != nil {
+			return 0, fmt.Errorf("failed to decode scalar result: %w", err)
+		}
+	case "vector":
+		var series []struct {
+			Value prometheusSample `json:"value"`
+		}
+		if err := json.Unmarshal(d.Result, &series); err != nil {
+			return 0, fmt.Errorf("failed to decode vector result: %w", err)
+		}
+		switch len(series) {
+		case 0:
+			return 0, fmt.Errorf("prometheus query %q returned no data", query)
+		case 1:
+			sample = series[0].Value
+		default:
+			return 0, fmt.Errorf("prometheus query %q returned %d series, aggregate it to one", query, len(series))
+		}
+	default:
+		return 0, fmt.Errorf("prometheus query %q returned a %s, not a single value", query, d.ResultType)
+	}
+
+	text, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("prometheus query %q returned an invalid sample", query)
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("prometheus query %q returned an invalid value %q: %w", query, text, err)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("prometheus query %q returned %s", query, text)
+	}
+	return value, nil
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// prometheusStub serves a canned response to instant queries and records the last query
+type prometheusStub struct {
+	status   int
+	response string
+	query    string
+}
+
+func (s *prometheusStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/v1/query" {
+		http.NotFound(w, r)
+		return
+	}
+	s.query = r.URL.Query().Get("query")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(s.status)
+	_, _ = w.Write([]byte(s.response))
+}
+
+func TestPrometheusPodValue(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout-7d9f8-abcde"}}
+	condition := remediationv1alpha1.Condition{
+		Type:  remediationv1alpha1.ErrorRate,
+		Query: `sum(rate(http_requests_total{namespace="{{ .Namespace }}",pod="{{ .Pod }}",code=~"5.."}[5m]))`,
+	}
+	wantQuery := `sum(rate(http_requests_total{namespace="shop",pod="checkout-7d9f8-abcde",code=~"5.."}[5m]))`
+
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		want     float64
+		wantErr  string
+	}{
+		{
+			name:     "vector with one series",
+			status:   http.StatusOK,
+			response: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000.123,"2.5"]}]}}`,
+			want:     2.5,
+		},
+		{
+			name:     "scalar",
+			status:   http.StatusOK,
+			response: `{"status":"success","data":{"resultType":"scalar","result":[1700000000.123,"0.25"]}}`,
+			want:     0.25,
+		},
+		{
+			name:     "no data",
+			status:   http.StatusOK,
+			response: `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			wantErr:  "returned no data",
+		},
+		{
+			name:   "several series",
+			status: http.StatusOK,
+			response: `{"status":"success","data":{"resultType":"vector","result":[` +
+				`{"metric":{"pod":"a"},"value":[1700000000,"1"]},{"metric":{"pod":"b"},"value":[1700000000,"2"]}]}}`,
+			wantErr: "returned 2 series",
+		},
+		{
+			name:     "range result",
+			status:   http.StatusOK,
+			response: `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			wantErr:  "not a single value",
+		},
+		{
+			name:     "not a number",
+			status:   http.StatusOK,
+			response: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"NaN"]}]}}`,
+			wantErr:  "returned NaN",
+		},
+		{
+			name:     "query error",
+			status:   http.StatusBadRequest,
+			response: `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			wantErr:  "bad_data: parse error",
+		},
+		{
+			name:     "not JSON",
+			status:   http.StatusBadGateway,
+			response: `<html>bad gateway</html>`,
+			wantErr:  "HTTP 502",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &prometheusStub{status: tt.status, response: tt.response}
+			server := httptest.NewServer(stub)
+			defer server.Close()
+
+			source, err := NewPrometheus(server.URL, server.Client())
+			if err != nil {
+				t.Fatalf("NewPrometheus returned error: %v", err)
+			}
+			got, err := source.PodValue(context.Background(), pod, condition)
+			if stub.query != wantQuery {
+				t.Errorf("query = %q, want %q", stub.query, wantQuery)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PodValue error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PodValue returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("PodValue = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrometheusUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	address := server.URL
+	server.Close()
+
+	source, err := NewPrometheus(address, nil)
+	if err != nil {
+		t.Fatalf("NewPrometheus returned error: %v", err)
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"}}
+	condition := remediationv1alpha1.Condition{Type: remediationv1alpha1.PromQL, Query: "up"}
+	if _, err := source.PodValue(context.Background(), pod, condition); err == nil {
+		t.Error("PodValue returned no error for an unreachable server")
+	}
+}
+
+func TestNewPrometheusAddress(t *testing.T) {
+	for _, address := range []string{"prometheus:9090", "ftp://prometheus", "://"} {
+		if _, err := NewPrometheus(address, nil); err == nil {
+			t.Errorf("NewPrometheus(%q) returned no error", address)
+		}
+	}
+}
+
+func TestResolveSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition remediationv1alpha1.Condition
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "CPU defaults to metrics-server",
+			condition: remediationv1alpha1.Condition{Type: remediationv1alpha1.CPUUsage},
+			want:      remediationv1alpha1.SourceMetricsServer,
+		},
+		{
+			name: "memory from the custom metrics API",
+			condition: remediationv1alpha1.Condition{
+				Type: remediationv1alpha1.MemoryUsage, Source: remediationv1alpha1.SourceCustom, Metric: "memory_rss",
+			},
+			want: remediationv1alpha1.SourceCustom,
+		},
+		{
+			name: "memory of one container",
+			condition: remediationv1alpha1.Condition{
+				Type: remediationv1alpha1.MemoryUsage, Container: "app",
+			},
+			want: remediationv1alpha1.SourceMetricsServer,
+		},
+		{
+			name:      "PromQL defaults to Prometheus",
+			condition: remediationv1alpha1.Condition{Type: remediationv1alpha1.PromQL, Query: `up{pod="{{ .Pod }}"}`},
+			want:      remediationv1alpha1.SourcePrometheus,
+		},
+		{
+			name: "error rate from external metrics",
+			condition: remediationv1alpha1.Condition{
+				Type: remediationv1alpha1.ErrorRate, Source: remediationv1alpha1.SourceExternal, Metric: "lb_5xx_rate",
+			},
+			want: remediationv1alpha1.SourceExternal,
+		},
+		{
+			name:      "restarts come from the pod status",
+			condition: remediationv1alpha1.Condition{Type: remediationv1alpha1.PodRestarts},
+			want:      "",
+		},
+		{
+			name:      "crash loops come from the pod status",
+			condition: remediationv1alpha1.Condition{Type: remediationv1alpha1.CrashLoopBackOff},
+			want:      "",
+		},
+		{
+			name:      "error rate without a source",
+			condition: remediationv1alpha1.Condition{Type: remediationv1alpha1.ErrorRate},
+			wantErr:   true,
+		},
+		{
+			name: "error rate from metrics-server",
+			condition: remediationv1alpha1.Condition{
+				Type: remediationv1alpha1.ErrorRate, Source: remediationv1alpha1.SourceMetricsServer,
+			},
+			wantErr: true,
+		},
+		{
+			name:      "Prometheus without a query",
+			condition: remediationv1alpha1.Condition{Type: remediationv1alpha1.PromQL},
+			wantErr:   true,
+		},
+		{
+			name:      "query with an unknown field",
+			condition: remediationv1alpha1.Condition{Type: remediationv1alpha1.PromQL, Query: `up{node="{{ .Node }}"}`},
+			wantErr:   true,
+		},
+		{
+			name:      "query that does not parse",
+			condition: remediationv1alpha1.Condition{Type: remediationv1alpha1.PromQL, Query: `up{pod="{{ .Pod }"}`},
+			wantErr:   true,
+		},
+		{
+			name: "PromQL from external metrics",
+			condition: remediationv1alpha1.Condition{
+				Type: remediationv1alpha1.PromQL, Source: remediationv1alpha1.SourceExternal, Metric: "queue_depth",
+			},
+			wantErr: true,
+		},
+		{
+			name:      "custom metric without a name",
+			condition: remediationv1alpha1.Condition{Type: "Latency", Source: remediationv1alpha1.SourceCustom},
+			wantErr:   true,
+		},
+		{
+			name: "container of a Prometheus condition",
+			condition: remediationv1alpha1.Condition{
+				Type: remediationv1alpha1.MemoryUsage, Source: remediationv1alpha1.SourcePrometheus,
+				Query: "container_memory_working_set_bytes", Container: "app",
+			},
+			wantErr: true,
+		},
+		{
+			name:      "container of a restart condition",
+			condition: remediationv1alpha1.Condition{Type: remediationv1alpha1.PodRestarts, Container: "app"},
+			wantErr:   true,
+		},
+		{
+			name: "restarts with a source",
+			condition: remediationv1alpha1.Condition{
+				Type: remediationv1alpha1.PodRestarts, Source: remediationv1alpha1.SourcePrometheus, Query: "up",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveSource(tt.condition)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ResolveSource = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveSource returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveSource = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
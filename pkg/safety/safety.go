@@ -0,0 +1,261 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package safety resolves the safety limits and protections configured by the
+// cluster-scoped KubeMedicConfig, shared by the admission webhook and the controller.
+package safety
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+const (
+	// ConfigName is the name of the KubeMedicConfig that configures the installation
+	ConfigName = "default"
+	// ProtectedLabel marks resources that remediation actions may not change
+	ProtectedLabel = "kubemedic.io/protected"
+	// ExcludeLabel marks namespaces excluded from remediation
+	ExcludeLabel = "kubemedic.io/exclude"
+)
+
+// DefaultDeniedNamespaces may not hold remediation policies unless the config says otherwise
+var DefaultDeniedNamespaces = []string{
+	"kube-system",
+	"kube-public",
+	"kube-node-lease",
+	"cert-manager",
+	"ingress-nginx",
+}
+
+// Limits bound the changes remediation actions may make
+type Limits struct {
+	// MaxScaleFactor is how many times its current replicas a target may be scaled to
+	MaxScaleFactor int32
+	// MinPods is the fewest replicas a target may be scaled to
+	MinPods int32
+	// MaxScalingDuration is the longest a temporary scaling change may last
+	MaxScalingDuration time.Duration
+	// MaxActionsPerHour limits the remediation actions per hour across the cluster; like
+	// the other action limits, zero means no limit
+	MaxActionsPerHour int32
+	// MaxActionsPerHourPerNamespace limits the remediation actions per hour in each namespace
+	MaxActionsPerHourPerNamespace int32
+	// MaxActionsPerHourPerPolicy limits the remediation actions per hour of each policy
+	MaxActionsPerHourPerPolicy int32
+	// MaxActionsPerHourPerTarget limits the remediation actions per hour on each target
+	MaxActionsPerHourPerTarget int32
+}
+
+// DefaultLimits returns the limits that apply when no KubeMedicConfig sets them
+func DefaultLimits() Limits {
+	return Limits{
+		MaxScaleFactor:     2,
+		MinPods:            1,
+		MaxScalingDuration: 2 * time.Hour,
+	}
+}
+
+// CheckScaling returns an error when scaling from current to requested replicas exceeds the limits
+func (l Limits) CheckScaling(current, requested int32) error {
+	if requested > current*l.MaxScaleFactor {
+		return fmt.Errorf("scale factor exceeds maximum allowed (%d)", l.MaxScaleFactor)
+	}
+	if requested < l.MinPods {
+		return fmt.Errorf("minimum pods cannot be less than %d", l.MinPods)
+	}
+	return nil
+}
+
+// CheckDuration returns an error when a temporary change lasts longer than the limits allow
+func (l Limits) CheckDuration(duration time.Duration) error {
+	if duration > l.MaxScalingDuration {
+		return fmt.Errorf("scaling duration exceeds maximum allowed (%v)", l.MaxScalingDuration)
+	}
+	return nil
+}
+
+// Settings are the effective settings for one namespace
+type Settings struct {
+	Limits            Limits
+	DeniedNamespaces  []string
+	AllowedNamespaces []string
+	// ClusterLimits are the limits before namespace overrides, for limits shared by all namespaces
+	ClusterLimits Limits
+	// Protected select resources that remediation actions may not change
+	Protected []labels.Selector
+	// Defaults are filled into policies that leave the settings unset
+	Defaults remediationv1alpha1.PolicyDefaults
+}
+
+// InvalidConfigError reports the settings of the KubeMedicConfig that could not be
+// applied. Load returns it along with usable settings, in which the defaults stand in for
+// the invalid settings, so that a bad configuration does not stop remediation.
+type InvalidConfigError struct {
+	Err error
+}
+
+func (e *InvalidConfigError) Error() string {
+	return fmt.Sprintf("KubeMedicConfig %s is invalid, using defaults for: %v", ConfigName, e.Err)
+}
+
+func (e *InvalidConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Load returns the settings for the namespace from the KubeMedicConfig named ConfigName,
+// or the defaults when it does not exist. When some settings of the config are invalid,
+// it returns the settings with defaults in their place and an *InvalidConfigError.
+func Load(ctx context.Context, c client.Reader, namespace string) (*Settings, error) {
+	var config remediationv1alpha1.KubeMedicConfig
+	if err := c.Get(ctx, client.ObjectKey{Name: ConfigName}, &config); err != nil {
+		if errors.IsNotFound(err) {
+			return Resolve(nil, namespace)
+		}
+		return nil, fmt.Errorf("failed to get KubeMedicConfig %s: %w", ConfigName, err)
+	}
+	return Resolve(&config, namespace)
+}
+
+// Resolve returns the settings for the namespace, applying the config's overrides for it
+// on top of its cluster-wide settings and those on top of the defaults. Invalid settings
+// are skipped and reported in an *InvalidConfigError returned with the settings.
+func Resolve(config *remediationv1alpha1.KubeMedicConfig, namespace string) (*Settings, error) {
+	protected, err := labels.Parse(ProtectedLabel + "=true")
+	if err != nil {
+		return nil, err
+	}
+	settings := &Settings{
+		Limits:           DefaultLimits(),
+		ClusterLimits:    DefaultLimits(),
+		DeniedNamespaces: DefaultDeniedNamespaces,
+		Protected:        []labels.Selector{protected},
+	}
+	if config == nil {
+		return settings, nil
+	}
+
+	var errs []error
+	spec := config.Spec
+	if spec.DeniedNamespaces != nil {
+		settings.DeniedNamespaces = spec.DeniedNamespaces
+	}
+	settings.AllowedNamespaces = spec.AllowedNamespaces
+	settings.Defaults = spec.PolicyDefaults
+	if spec.PolicyDefaults.CooldownPeriod != "" {
+		if _, err := time.ParseDuration(spec.PolicyDefaults.CooldownPeriod); err != nil {
+			errs = append(errs, fmt.Errorf("invalid default cooldown period %q: %w", spec.PolicyDefaults.CooldownPeriod, err))
+			settings.Defaults.CooldownPeriod = ""
+		}
+	}
+	errs = append(errs, settings.applyLimits(spec.SafetyLimits)...)
+	settings.ClusterLimits = settings.Limits
+	errs = append(errs, settings.addProtected(spec.ProtectedSelectors)...)
+
+	for _, override := range spec.NamespaceOverrides {
+		if override.Namespace != namespace {
+			continue
+		}
+		for _, err := range settings.applyLimits(override.SafetyLimits) {
+			errs = append(errs, fmt.Errorf("namespace override %s: %w", namespace, err))
+		}
+		for _, err := range settings.addProtected(override.ProtectedSelectors) {
+			errs = append(errs, fmt.Errorf("namespace override %s: %w", namespace, err))
+		}
+	}
+	if len(errs) > 0 {
+		return settings, &InvalidConfigError{Err: goerrors.Join(errs...)}
+	}
+	return settings, nil
+}
+
+// applyLimits replaces the limits that are set and valid, returning the invalid ones
+func (s *Settings) applyLimits(limits remediationv1alpha1.SafetyLimits) []error {
+	var errs []error
+	if limits.MaxScaleFactor != nil {
+		s.Limits.MaxScaleFactor = *limits.MaxScaleFactor
+	}
+	if limits.MinPods != nil {
+		s.Limits.MinPods = *limits.MinPods
+	}
+	if limits.MaxScalingDuration != "" {
+		duration, err := time.ParseDuration(limits.MaxScalingDuration)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid max scaling duration %q: %w", limits.MaxScalingDuration, err))
+		} else {
+			s.Limits.MaxScalingDuration = duration
+		}
+	}
+	if limits.MaxActionsPerHour != nil {
+		s.Limits.MaxActionsPerHour = *limits.MaxActionsPerHour
+	}
+	if limits.MaxActionsPerHourPerNamespace != nil {
+		s.Limits.MaxActionsPerHourPerNamespace = *limits.MaxActionsPerHourPerNamespace
+	}
+	if limits.MaxActionsPerHourPerPolicy != nil {
+		s.Limits.MaxActionsPerHourPerPolicy = *limits.MaxActionsPerHourPerPolicy
+	}
+	if limits.MaxActionsPerHourPerTarget != nil {
+		s.Limits.MaxActionsPerHourPerTarget = *limits.MaxActionsPerHourPerTarget
+	}
+	return errs
+}
+
+// addProtected adds the valid selectors of protected resources, returning the invalid ones
+func (s *Settings) addProtected(selectors []metav1.LabelSelector) []error {
+	var errs []error
+	for i := range selectors {
+		selector, err := metav1.LabelSelectorAsSelector(&selectors[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid protected selector: %w", err))
+			continue
+		}
+		s.Protected = append(s.Protected, selector)
+	}
+	return errs
+}
+
+// CheckNamespace returns an error when remediation is not allowed in the namespace
+func (s *Settings) CheckNamespace(namespace string) error {
+	if slices.Contains(s.DeniedNamespaces, namespace) {
+		return fmt.Errorf("namespace %s is not allowed for remediation policies", namespace)
+	}
+	if len(s.AllowedNamespaces) > 0 && !slices.Contains(s.AllowedNamespaces, namespace) {
+		return fmt.Errorf("namespace %s is not in the allowed namespaces", namespace)
+	}
+	return nil
+}
+
+// IsProtected reports whether a resource with the labels may not be changed
+func (s *Settings) IsProtected(resourceLabels map[string]string) bool {
+	set := labels.Set(resourceLabels)
+	for _, selector := range s.Protected {
+		if selector.Matches(set) {
+			return true
+		}
+	}
+	return false
+}
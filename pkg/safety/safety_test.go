@@ -0,0 +1,104 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safety
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+func TestResolveInvalidSettings(t *testing.T) {
+	factor := int32(4)
+	tests := []struct {
+		name         string
+		spec         remediationv1alpha1.KubeMedicConfigSpec
+		wantInvalid  bool
+		wantDuration time.Duration
+		wantFactor   int32
+		wantCooldown string
+	}{
+		{
+			name:         "valid",
+			spec:         remediationv1alpha1.KubeMedicConfigSpec{SafetyLimits: remediationv1alpha1.SafetyLimits{MaxScalingDuration: "30m"}},
+			wantDuration: 30 * time.Minute,
+			wantFactor:   2,
+		},
+		{
+			name: "invalid duration keeps the other limits",
+			spec: remediationv1alpha1.KubeMedicConfigSpec{SafetyLimits: remediationv1alpha1.SafetyLimits{
+				MaxScalingDuration: "two hours",
+				MaxScaleFactor:     &factor,
+			}},
+			wantInvalid:  true,
+			wantDuration: 2 * time.Hour,
+			wantFactor:   4,
+		},
+		{
+			name: "invalid override",
+			spec: remediationv1alpha1.KubeMedicConfigSpec{NamespaceOverrides: []remediationv1alpha1.NamespaceOverride{{
+				Namespace:    "shop",
+				SafetyLimits: remediationv1alpha1.SafetyLimits{MaxScalingDuration: "1 day"},
+			}}},
+			wantInvalid:  true,
+			wantDuration: 2 * time.Hour,
+			wantFactor:   2,
+		},
+		{
+			name: "invalid default cooldown",
+			spec: remediationv1alpha1.KubeMedicConfigSpec{PolicyDefaults: remediationv1alpha1.PolicyDefaults{
+				CooldownPeriod: "soon",
+			}},
+			wantInvalid:  true,
+			wantDuration: 2 * time.Hour,
+			wantFactor:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &remediationv1alpha1.KubeMedicConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: ConfigName},
+				Spec:       tt.spec,
+			}
+			settings, err := Resolve(config, "shop")
+			var invalid *InvalidConfigError
+			if errors.As(err, &invalid) != tt.wantInvalid {
+				t.Fatalf("Resolve() error = %v, want invalid %v", err, tt.wantInvalid)
+			}
+			if err != nil && invalid == nil {
+				t.Fatalf("Resolve() returned error: %v", err)
+			}
+			if settings == nil {
+				t.Fatal("Resolve() returned no settings")
+			}
+			if settings.Limits.MaxScalingDuration != tt.wantDuration {
+				t.Errorf("MaxScalingDuration = %v, want %v", settings.Limits.MaxScalingDuration, tt.wantDuration)
+			}
+			if settings.Limits.MaxScaleFactor != tt.wantFactor {
+				t.Errorf("MaxScaleFactor = %d, want %d", settings.Limits.MaxScaleFactor, tt.wantFactor)
+			}
+			if settings.Defaults.CooldownPeriod != tt.wantCooldown {
+				t.Errorf("CooldownPeriod = %q, want %q", settings.Defaults.CooldownPeriod, tt.wantCooldown)
+			}
+		})
+	}
+}
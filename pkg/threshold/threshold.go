@@ -0,0 +1,330 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package threshold parses the thresholds of policy conditions, shared by the admission
+// webhook, which rejects invalid thresholds, and the controller, which evaluates them.
+//
+// A threshold is a value with an optional comparison operator, or a range:
+//
+//	80%          over 80 percent (">" is the default operator)
+//	>=500m       at least half a core
+//	<2Gi         under 2 GiB
+//	==0          exactly zero
+//	5/min        over five per minute
+//	60%..80%     between 60 and 80 percent, inclusive
+package threshold
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+// rangeSeparator separates the ends of a range threshold
+const rangeSeparator = ".."
+
+// Unit is what the absolute values of a threshold measure
+type Unit int
+
+const (
+	// Number thresholds are plain numbers, such as counts ("3")
+	Number Unit = iota
+	// Cores thresholds are CPU quantities ("500m", "2")
+	Cores
+	// Bytes thresholds are memory quantities ("512Mi", "2Gi")
+	Bytes
+)
+
+// Operator compares an observed value against a threshold
+type Operator string
+
+const (
+	GreaterThan        Operator = ">"
+	GreaterThanOrEqual Operator = ">="
+	LessThan           Operator = "<"
+	LessThanOrEqual    Operator = "<="
+	Equal              Operator = "=="
+)
+
+// operators are tried in order, so longer operators come before their prefixes
+var operators = []struct {
+	text     string
+	operator Operator
+}{
+	{">=", GreaterThanOrEqual},
+	{"<=", LessThanOrEqual},
+	{"==", Equal},
+	{">", GreaterThan},
+	{"<", LessThan},
+	{"=", Equal},
+}
+
+// ratePeriods maps the periods a rate may be written per to their canonical names
+var ratePeriods = map[string]struct {
+	name   string
+	period time.Duration
+}{
+	"s":      {"s", time.Second},
+	"sec":    {"s", time.Second},
+	"second": {"s", time.Second},
+	"min":    {"min", time.Minute},
+	"minute": {"min", time.Minute},
+	"h":      {"h", time.Hour},
+	"hr":     {"h", time.Hour},
+	"hour":   {"h", time.Hour},
+}
+
+// Threshold is a parsed condition threshold
+type Threshold struct {
+	// Operator compares the observed value against Value; it is empty for ranges
+	Operator Operator
+	// Value is the threshold, or the lower end of a range, in the unit it was parsed with
+	// (cores, bytes or a number). It is a percentage when Percent is set, and a rate per
+	// second when Per is set.
+	Value float64
+	// Max is the upper end of a range
+	Max float64
+	// Range is set for thresholds met by values from Value to Max, inclusive
+	Range bool
+	// Percent is set for thresholds relative to the requests or limits of the target
+	Percent bool
+	// Per is the period a rate threshold was written with, zero for other thresholds
+	Per time.Duration
+	// Bare is set when a cores or bytes value was written as a plain number, without a
+	// unit suffix, so that "80" means 80 cores or 80 bytes rather than 80 percent
+	Bare bool
+
+	canonical string
+}
+
+// Parse parses a threshold whose absolute values are in the given unit
+func Parse(raw string, unit Unit) (Threshold, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return Threshold{}, fmt.Errorf("threshold must not be empty")
+	}
+
+	if low, high, ok := strings.Cut(value, rangeSeparator); ok {
+		lower, err := parseBound(low, unit)
+		if err != nil {
+			return Threshold{}, fmt.Errorf("invalid threshold %q: %w", raw, err)
+		}
+		upper, err := parseBound(high, unit)
+		if err != nil {
+			return Threshold{}, fmt.Errorf("invalid threshold %q: %w", raw, err)
+		}
+		if lower.percent != upper.percent || lower.per != upper.per {
+			return Threshold{}, fmt.Errorf("invalid threshold %q: range ends must be in the same unit", raw)
+		}
+		if lower.value > upper.value {
+			return Threshold{}, fmt.Errorf("invalid threshold %q: range start is greater than its end", raw)
+		}
+		return Threshold{
+			Value:     lower.value,
+			Max:       upper.value,
+			Range:     true,
+			Percent:   lower.percent,
+			Per:       lower.per,
+			Bare:      lower.bare || upper.bare,
+			canonical: lower.text + rangeSeparator + upper.text,
+		}, nil
+	}
+
+	operator, prefix := GreaterThan, ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(value, candidate.text) {
+			operator = candidate.operator
+			value = strings.TrimPrefix(value, candidate.text)
+			prefix = string(operator)
+			break
+		}
+	}
+	// The default operator is left out of the canonical form
+	if operator == GreaterThan {
+		prefix = ""
+	}
+
+	bound, err := parseBound(value, unit)
+	if err != nil {
+		return Threshold{}, fmt.Errorf("invalid threshold %q: %w", raw, err)
+	}
+	return Threshold{
+		Operator:  operator,
+		Value:     bound.value,
+		Percent:   bound.percent,
+		Per:       bound.per,
+		Bare:      bound.bare,
+		canonical: prefix + bound.text,
+	}, nil
+}
+
+// ForCondition parses the threshold of a condition, in the unit of its type: cores for
+// CPUUsage, bytes for MemoryUsage and numbers otherwise. Percentages are accepted for
+// resource usage, error rates and queries, and rates only for error rates, queries and
+// pod restarts.
+func ForCondition(conditionType remediationv1alpha1.ConditionType, raw string) (Threshold, error) {
+	unit := Number
+	percent, rate := false, false
+	switch conditionType {
+	case remediationv1alpha1.CPUUsage:
+		unit, percent = Cores, true
+	case remediationv1alpha1.MemoryUsage:
+		unit, percent = Bytes, true
+	case remediationv1alpha1.ErrorRate, remediationv1alpha1.PromQL:
+		percent, rate = true, true
+	case remediationv1alpha1.PodRestarts:
+		rate = true
+	}
+
+	threshold, err := Parse(raw, unit)
+	if err != nil {
+		return Threshold{}, err
+	}
+	if threshold.Percent && !percent {
+		return Threshold{}, fmt.Errorf("percentage thresholds are not supported for %s conditions", conditionType)
+	}
+	if threshold.IsRate() && !rate {
+		return Threshold{}, fmt.Errorf("rate thresholds are not supported for %s conditions", conditionType)
+	}
+	return threshold, nil
+}
+
+// Met reports whether the observed value, in the unit of the threshold, meets it
+func (t Threshold) Met(observed float64) bool {
+	if t.Range {
+		return observed >= t.Value && observed <= t.Max
+	}
+	switch t.Operator {
+	case GreaterThanOrEqual:
+		return observed >= t.Value
+	case LessThan:
+		return observed < t.Value
+	case LessThanOrEqual:
+		return observed <= t.Value
+	case Equal:
+		return observed == t.Value
+	default:
+		return observed > t.Value
+	}
+}
+
+// Below reports whether the threshold is met by values below it, so that a remediation
+// improves the condition by raising the value
+func (t Threshold) Below() bool {
+	return !t.Range && (t.Operator == LessThan || t.Operator == LessThanOrEqual)
+}
+
+// IsRate reports whether the threshold is a rate, such as "5/min"
+func (t Threshold) IsRate() bool {
+	return t.Per > 0
+}
+
+// String returns the canonical form of the threshold, such as "80%", ">=500m" or "1Gi"
+func (t Threshold) String() string {
+	return t.canonical
+}
+
+// bound is one parsed value of a threshold
+type bound struct {
+	value   float64
+	percent bool
+	per     time.Duration
+	bare    bool
+	// text is the canonical form of the value
+	text string
+}
+
+// parseBound parses a percentage, a rate or an absolute value in the unit
+func parseBound(raw string, unit Unit) (bound, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return bound{}, fmt.Errorf("value must not be empty")
+	}
+
+	if count, period, ok := strings.Cut(value, "/"); ok {
+		per, ok := ratePeriods[strings.ToLower(strings.TrimSpace(period))]
+		if !ok {
+			return bound{}, fmt.Errorf("unknown rate period %q, use s, min or h", strings.TrimSpace(period))
+		}
+		number, err := parseNumber(count)
+		if err != nil {
+			return bound{}, err
+		}
+		return bound{
+			value: number / per.period.Seconds(),
+			per:   per.period,
+			text:  formatNumber(number) + "/" + per.name,
+		}, nil
+	}
+
+	if strings.HasSuffix(value, "%") {
+		percent, err := parseNumber(strings.TrimSuffix(value, "%"))
+		if err != nil {
+			return bound{}, err
+		}
+		return bound{value: percent, percent: true, text: formatNumber(percent) + "%"}, nil
+	}
+
+	switch unit {
+	case Cores, Bytes:
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return bound{}, fmt.Errorf("invalid quantity %q: %w", value, err)
+		}
+		if quantity.Sign() < 0 {
+			return bound{}, fmt.Errorf("value must not be negative")
+		}
+		_, err = strconv.ParseFloat(value, 64)
+		bare := err == nil
+		if unit == Cores {
+			return bound{value: float64(quantity.MilliValue()) / 1000.0, bare: bare, text: quantity.String()}, nil
+		}
+		return bound{value: float64(quantity.Value()), bare: bare, text: quantity.String()}, nil
+
+	default:
+		number, err := parseNumber(value)
+		if err != nil {
+			return bound{}, err
+		}
+		return bound{value: number, text: formatNumber(number)}, nil
+	}
+}
+
+// parseNumber parses a finite, non-negative number
+func parseNumber(raw string) (float64, error) {
+	number, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", strings.TrimSpace(raw))
+	}
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("value must be a finite number")
+	}
+	if number < 0 {
+		return 0, fmt.Errorf("value must not be negative")
+	}
+	return number, nil
+}
+
+// formatNumber formats a number in its shortest form
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
@@ -0,0 +1,402 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package threshold
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		unit      Unit
+		want      Threshold
+		canonical string
+	}{
+		{
+			name:      "percentage",
+			raw:       "80%",
+			unit:      Cores,
+			want:      Threshold{Operator: GreaterThan, Value: 80, Percent: true},
+			canonical: "80%",
+		},
+		{
+			name:      "percentage with spaces",
+			raw:       "  80 % ",
+			unit:      Bytes,
+			want:      Threshold{Operator: GreaterThan, Value: 80, Percent: true},
+			canonical: "80%",
+		},
+		{
+			name:      "fractional percentage",
+			raw:       "99.5%",
+			unit:      Number,
+			want:      Threshold{Operator: GreaterThan, Value: 99.5, Percent: true},
+			canonical: "99.5%",
+		},
+		{
+			name:      "millicores",
+			raw:       "500m",
+			unit:      Cores,
+			want:      Threshold{Operator: GreaterThan, Value: 0.5},
+			canonical: "500m",
+		},
+		{
+			name:      "fractional cores",
+			raw:       "0.5",
+			unit:      Cores,
+			want:      Threshold{Operator: GreaterThan, Value: 0.5},
+			canonical: "500m",
+		},
+		{
+			name:      "whole cores",
+			raw:       "2",
+			unit:      Cores,
+			want:      Threshold{Operator: GreaterThan, Value: 2},
+			canonical: "2",
+		},
+		{
+			name:      "binary bytes",
+			raw:       "512Mi",
+			unit:      Bytes,
+			want:      Threshold{Operator: GreaterThan, Value: 512 * 1024 * 1024},
+			canonical: "512Mi",
+		},
+		{
+			name:      "bytes in canonical form",
+			raw:       "1024Mi",
+			unit:      Bytes,
+			want:      Threshold{Operator: GreaterThan, Value: 1024 * 1024 * 1024},
+			canonical: "1Gi",
+		},
+		{
+			name:      "decimal bytes",
+			raw:       "1G",
+			unit:      Bytes,
+			want:      Threshold{Operator: GreaterThan, Value: 1e9},
+			canonical: "1G",
+		},
+		{
+			name:      "count",
+			raw:       "2",
+			unit:      Number,
+			want:      Threshold{Operator: GreaterThan, Value: 2},
+			canonical: "2",
+		},
+		{
+			name:      "count in canonical form",
+			raw:       "3.0",
+			unit:      Number,
+			want:      Threshold{Operator: GreaterThan, Value: 3},
+			canonical: "3",
+		},
+		{
+			name:      "explicit default operator",
+			raw:       "> 80%",
+			unit:      Cores,
+			want:      Threshold{Operator: GreaterThan, Value: 80, Percent: true},
+			canonical: "80%",
+		},
+		{
+			name:      "greater than or equal",
+			raw:       ">=500m",
+			unit:      Cores,
+			want:      Threshold{Operator: GreaterThanOrEqual, Value: 0.5},
+			canonical: ">=500m",
+		},
+		{
+			name:      "less than",
+			raw:       "<2Gi",
+			unit:      Bytes,
+			want:      Threshold{Operator: LessThan, Value: 2 * 1024 * 1024 * 1024},
+			canonical: "<2Gi",
+		},
+		{
+			name:      "less than or equal",
+			raw:       "<= 10",
+			unit:      Number,
+			want:      Threshold{Operator: LessThanOrEqual, Value: 10},
+			canonical: "<=10",
+		},
+		{
+			name:      "equal",
+			raw:       "==0",
+			unit:      Number,
+			want:      Threshold{Operator: Equal, Value: 0},
+			canonical: "==0",
+		},
+		{
+			name:      "single equals sign",
+			raw:       "=0",
+			unit:      Number,
+			want:      Threshold{Operator: Equal, Value: 0},
+			canonical: "==0",
+		},
+		{
+			name:      "rate per minute",
+			raw:       "5/min",
+			unit:      Number,
+			want:      Threshold{Operator: GreaterThan, Value: 5.0 / 60, Per: time.Minute},
+			canonical: "5/min",
+		},
+		{
+			name:      "rate per second",
+			raw:       "0.5/sec",
+			unit:      Number,
+			want:      Threshold{Operator: GreaterThan, Value: 0.5, Per: time.Second},
+			canonical: "0.5/s",
+		},
+		{
+			name:      "rate per hour with operator",
+			raw:       ">= 36 / Hour",
+			unit:      Number,
+			want:      Threshold{Operator: GreaterThanOrEqual, Value: 0.01, Per: time.Hour},
+			canonical: ">=36/h",
+		},
+		{
+			name:      "percentage range",
+			raw:       "60%..80%",
+			unit:      Cores,
+			want:      Threshold{Value: 60, Max: 80, Range: true, Percent: true},
+			canonical: "60%..80%",
+		},
+		{
+			name:      "quantity range",
+			raw:       "0.5 .. 1",
+			unit:      Cores,
+			want:      Threshold{Value: 0.5, Max: 1, Range: true},
+			canonical: "500m..1",
+		},
+		{
+			name:      "single value range",
+			raw:       "3..3",
+			unit:      Number,
+			want:      Threshold{Value: 3, Max: 3, Range: true},
+			canonical: "3..3",
+		},
+		{
+			name:      "rate range",
+			raw:       "1/min..2/min",
+			unit:      Number,
+			want:      Threshold{Value: 1.0 / 60, Max: 2.0 / 60, Range: true, Per: time.Minute},
+			canonical: "1/min..2/min",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw, tt.unit)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.raw, err)
+			}
+			if got.Operator != tt.want.Operator || !approximately(got.Value, tt.want.Value) ||
+				!approximately(got.Max, tt.want.Max) || got.Range != tt.want.Range ||
+				got.Percent != tt.want.Percent || got.Per != tt.want.Per {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+			if got.String() != tt.canonical {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.raw, got.String(), tt.canonical)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		unit Unit
+	}{
+		{name: "empty", raw: "", unit: Number},
+		{name: "blank", raw: "   ", unit: Cores},
+		{name: "operator only", raw: ">=", unit: Number},
+		{name: "not a number", raw: "high", unit: Number},
+		{name: "quantity for a number", raw: "500m", unit: Number},
+		{name: "invalid quantity", raw: "5 cores", unit: Cores},
+		{name: "invalid byte quantity", raw: "2GB", unit: Bytes},
+		{name: "invalid percentage", raw: "eighty%", unit: Cores},
+		{name: "percent sign only", raw: "%", unit: Cores},
+		{name: "negative number", raw: "-1", unit: Number},
+		{name: "negative percentage", raw: "-5%", unit: Cores},
+		{name: "negative quantity", raw: "-500m", unit: Cores},
+		{name: "not finite", raw: "NaN", unit: Number},
+		{name: "infinite", raw: "Inf", unit: Number},
+		{name: "unknown rate period", raw: "5/day", unit: Number},
+		{name: "missing rate period", raw: "5/", unit: Number},
+		{name: "quantity rate", raw: "500m/min", unit: Number},
+		{name: "unknown operator", raw: "!=3", unit: Number},
+		{name: "doubled operator", raw: ">>3", unit: Number},
+		{name: "range without start", raw: "..80%", unit: Cores},
+		{name: "range without end", raw: "60%..", unit: Cores},
+		{name: "reversed range", raw: "80%..60%", unit: Cores},
+		{name: "range of mixed units", raw: "60%..1", unit: Cores},
+		{name: "range of mixed rates", raw: "1/min..1/h", unit: Number},
+		{name: "range with operator", raw: ">1..2", unit: Number},
+		{name: "range of three", raw: "1..2..3", unit: Number},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Parse(tt.raw, tt.unit); err == nil {
+				t.Errorf("Parse(%q) = %+v, want error", tt.raw, got)
+			}
+		})
+	}
+}
+
+func TestForCondition(t *testing.T) {
+	tests := []struct {
+		name          string
+		conditionType remediationv1alpha1.ConditionType
+		raw           string
+		wantValue     float64
+		wantErr       bool
+	}{
+		{name: "CPU percentage", conditionType: remediationv1alpha1.CPUUsage, raw: "80%", wantValue: 80},
+		{name: "CPU cores", conditionType: remediationv1alpha1.CPUUsage, raw: "500m", wantValue: 0.5},
+		{name: "memory percentage", conditionType: remediationv1alpha1.MemoryUsage, raw: "90%", wantValue: 90},
+		{name: "memory bytes", conditionType: remediationv1alpha1.MemoryUsage, raw: "1Ki", wantValue: 1024},
+		{name: "restart count", conditionType: remediationv1alpha1.PodRestarts, raw: "3", wantValue: 3},
+		{name: "restart rate", conditionType: remediationv1alpha1.PodRestarts, raw: "6/h", wantValue: 6.0 / 3600},
+		{name: "crash looping containers", conditionType: remediationv1alpha1.CrashLoopBackOff, raw: ">=1", wantValue: 1},
+		{name: "error rate percentage", conditionType: remediationv1alpha1.ErrorRate, raw: "5%", wantValue: 5},
+		{name: "error rate per second", conditionType: remediationv1alpha1.ErrorRate, raw: "10/s", wantValue: 10},
+		{name: "query rate", conditionType: remediationv1alpha1.PromQL, raw: "<= 30/min", wantValue: 0.5},
+		{name: "query number", conditionType: remediationv1alpha1.PromQL, raw: "0.25", wantValue: 0.25},
+		{name: "CPU rate", conditionType: remediationv1alpha1.CPUUsage, raw: "5/min", wantErr: true},
+		{name: "memory rate", conditionType: remediationv1alpha1.MemoryUsage, raw: "1/h", wantErr: true},
+		{name: "restart percentage", conditionType: remediationv1alpha1.PodRestarts, raw: "50%", wantErr: true},
+		{name: "restart quantity", conditionType: remediationv1alpha1.PodRestarts, raw: "2k", wantErr: true},
+		{name: "OOM kill rate", conditionType: remediationv1alpha1.OOMKilled, raw: "1/h", wantErr: true},
+		{name: "invalid memory", conditionType: remediationv1alpha1.MemoryUsage, raw: "lots", wantErr: true},
+		{name: "unknown condition number", conditionType: "Latency", raw: "250", wantValue: 250},
+		{name: "unknown condition percentage", conditionType: "Latency", raw: "25%", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ForCondition(tt.conditionType, tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ForCondition(%s, %q) = %+v, want error", tt.conditionType, tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ForCondition(%s, %q) returned error: %v", tt.conditionType, tt.raw, err)
+			}
+			if !approximately(got.Value, tt.wantValue) {
+				t.Errorf("ForCondition(%s, %q).Value = %v, want %v", tt.conditionType, tt.raw, got.Value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestBare(t *testing.T) {
+	tests := []struct {
+		conditionType remediationv1alpha1.ConditionType
+		raw           string
+		want          bool
+	}{
+		{conditionType: remediationv1alpha1.CPUUsage, raw: "80", want: true},
+		{conditionType: remediationv1alpha1.CPUUsage, raw: "80%", want: false},
+		{conditionType: remediationv1alpha1.CPUUsage, raw: ">=500m", want: false},
+		{conditionType: remediationv1alpha1.MemoryUsage, raw: "90", want: true},
+		{conditionType: remediationv1alpha1.MemoryUsage, raw: "1024..1Mi", want: true},
+		{conditionType: remediationv1alpha1.MemoryUsage, raw: "512Mi", want: false},
+		{conditionType: remediationv1alpha1.PodRestarts, raw: "3", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.conditionType)+" "+tt.raw, func(t *testing.T) {
+			got, err := ForCondition(tt.conditionType, tt.raw)
+			if err != nil {
+				t.Fatalf("ForCondition(%s, %q) returned error: %v", tt.conditionType, tt.raw, err)
+			}
+			if got.Bare != tt.want {
+				t.Errorf("ForCondition(%s, %q).Bare = %v, want %v", tt.conditionType, tt.raw, got.Bare, tt.want)
+			}
+		})
+	}
+}
+
+func TestMet(t *testing.T) {
+	tests := []struct {
+		raw      string
+		observed float64
+		want     bool
+	}{
+		{raw: "80", observed: 81, want: true},
+		{raw: "80", observed: 80, want: false},
+		{raw: "80", observed: 79, want: false},
+		{raw: ">=80", observed: 80, want: true},
+		{raw: ">=80", observed: 79.9, want: false},
+		{raw: "<10", observed: 9, want: true},
+		{raw: "<10", observed: 10, want: false},
+		{raw: "<=10", observed: 10, want: true},
+		{raw: "<=10", observed: 10.1, want: false},
+		{raw: "==0", observed: 0, want: true},
+		{raw: "==0", observed: 1, want: false},
+		{raw: "60..80", observed: 60, want: true},
+		{raw: "60..80", observed: 70, want: true},
+		{raw: "60..80", observed: 80, want: true},
+		{raw: "60..80", observed: 59, want: false},
+		{raw: "60..80", observed: 81, want: false},
+		{raw: "6/min", observed: 0.2, want: true},
+		{raw: "6/min", observed: 0.1, want: false},
+	}
+
+	for _, tt := range tests {
+		threshold, err := Parse(tt.raw, Number)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", tt.raw, err)
+		}
+		if got := threshold.Met(tt.observed); got != tt.want {
+			t.Errorf("Parse(%q).Met(%v) = %v, want %v", tt.raw, tt.observed, got, tt.want)
+		}
+	}
+}
+
+func TestBelow(t *testing.T) {
+	tests := map[string]bool{
+		"80":     false,
+		">=80":   false,
+		"==80":   false,
+		"<80":    true,
+		"<=80":   true,
+		"60..80": false,
+	}
+
+	for raw, want := range tests {
+		threshold, err := Parse(raw, Number)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", raw, err)
+		}
+		if got := threshold.Below(); got != want {
+			t.Errorf("Parse(%q).Below() = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func approximately(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+	"github.com/ikepcampbell/kubemedic/pkg/safety"
+	"github.com/ikepcampbell/kubemedic/pkg/threshold"
+)
+
+// kindAliases maps the lower-cased names users write for target kinds to the kinds the
+// controller understands
+var kindAliases = map[string]string{
+	"deployment":              "Deployment",
+	"deploy":                  "Deployment",
+	"statefulset":             "StatefulSet",
+	"sts":                     "StatefulSet",
+	"daemonset":               "DaemonSet",
+	"ds":                      "DaemonSet",
+	"horizontalpodautoscaler": "HorizontalPodAutoscaler",
+	"hpa":                     "HorizontalPodAutoscaler",
+	"pod":                     "Pod",
+}
+
+// KubeMedicDefaulter fills in defaults and normalizes SelfRemediationPolicy resources
+type KubeMedicDefaulter struct {
+	Client  client.Client
+	decoder admission.Decoder
+}
+
+// NewKubeMedicDefaulter creates a defaulter that decodes policies with the given scheme
+func NewKubeMedicDefaulter(c client.Client, scheme *runtime.Scheme) *KubeMedicDefaulter {
+	if c == nil {
+		panic("client cannot be nil")
+	}
+	if scheme == nil {
+		panic("scheme cannot be nil")
+	}
+	return &KubeMedicDefaulter{
+		Client:  c,
+		decoder: admission.NewDecoder(scheme),
+	}
+}
+
+// Handle patches SelfRemediationPolicy resources with their defaults. Policies that cannot
+// be decoded are denied.
+func (d *KubeMedicDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	log := log.FromContext(ctx).WithValues(
+		"webhook", "defaulter",
+		"namespace", req.Namespace,
+		"name", req.Name,
+		"operation", req.Operation,
+	)
+
+	if req.Object.Raw == nil {
+		log.Error(nil, "No object provided")
+		return admission.Denied("no object to default")
+	}
+
+	policy := &remediationv1alpha1.SelfRemediationPolicy{}
+	if err := d.decoder.Decode(req, policy); err != nil {
+		log.Error(err, "Failed to decode admission request")
+		return admission.Denied(fmt.Sprintf("failed to decode SelfRemediationPolicy: %v", err))
+	}
+	namespace := policy.Namespace
+	if namespace == "" {
+		namespace = req.Namespace
+	}
+
+	// An invalid configuration falls back to the defaults for its invalid settings rather
+	// than rejecting every policy
+	var warnings []string
+	settings, err := safety.Load(ctx, d.Client, namespace)
+	var invalid *safety.InvalidConfigError
+	if errors.As(err, &invalid) {
+		log.Error(err, "Ignoring invalid KubeMedic configuration")
+		warnings = append(warnings, invalid.Error())
+	} else if err != nil {
+		log.Error(err, "Failed to load KubeMedic configuration")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	defaultPolicy(policy, namespace, settings.Defaults)
+
+	marshaled, err := json.Marshal(policy)
+	if err != nil {
+		log.Error(err, "Failed to encode defaulted policy")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled).WithWarnings(warnings...)
+}
+
+// defaultPolicy fills in the unset settings of the policy and normalizes target kinds and
+// thresholds, in place
+func defaultPolicy(
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	namespace string,
+	defaults remediationv1alpha1.PolicyDefaults,
+) {
+	spec := &policy.Spec
+	if spec.TargetRef.Namespace == "" {
+		spec.TargetRef.Namespace = namespace
+	}
+	spec.TargetRef.Kind = canonicalKind(spec.TargetRef.Kind)
+	if spec.CooldownPeriod == "" {
+		spec.CooldownPeriod = defaults.CooldownPeriod
+	}
+	if spec.CPUThreshold != "" {
+		spec.CPUThreshold = canonicalThreshold(remediationv1alpha1.CPUUsage, spec.CPUThreshold)
+	}
+
+	for i := range spec.Rules {
+		rule := &spec.Rules[i]
+		for j := range rule.Conditions {
+			condition := &rule.Conditions[j]
+			condition.Threshold = canonicalThreshold(condition.Type, condition.Threshold)
+		}
+
+		for j := range rule.Actions {
+			action := &rule.Actions[j]
+			action.Target.Kind = canonicalKind(action.Target.Kind)
+			// Targets left empty on purpose are resolved from the targetRef pod
+			if action.Target.Namespace == "" && (action.Target.Kind != "" || action.Target.Name != "") {
+				action.Target.Namespace = namespace
+			}
+			if action.ScalingParams != nil && action.ScalingParams.RevertStrategy == "" {
+				action.ScalingParams.RevertStrategy = defaults.RevertStrategy
+				if action.ScalingParams.RevertStrategy == "" {
+					action.ScalingParams.RevertStrategy = remediationv1alpha1.RevertImmediate
+				}
+			}
+			if action.ConflictResolution == "" {
+				action.ConflictResolution = defaults.ConflictResolution
+			}
+		}
+	}
+}
+
+// canonicalKind returns the kind the controller understands for a kind alias
+func canonicalKind(kind string) string {
+	if canonical, ok := kindAliases[strings.ToLower(strings.TrimSpace(kind))]; ok {
+		return canonical
+	}
+	return kind
+}
+
+// canonicalThreshold returns the canonical form of a threshold, such as "80%" for "80 %"
+// or "500m" for CPU written as "0.5". Thresholds that do not parse are left for
+// validation to report.
+func canonicalThreshold(conditionType remediationv1alpha1.ConditionType, raw string) string {
+	parsed, err := threshold.ForCondition(conditionType, raw)
+	if err != nil {
+		return raw
+	}
+	return parsed.String()
+}
@@ -2,27 +2,56 @@ package webhook
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	admissionv1 "k8s.io/api/admission/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+	"github.com/ikepcampbell/kubemedic/pkg/metrics"
+	"github.com/ikepcampbell/kubemedic/pkg/safety"
+	"github.com/ikepcampbell/kubemedic/pkg/threshold"
 )
 
+// namespacePath is the field path of the policy's namespace
+const namespacePath = "metadata.namespace"
+
 // KubeMedicValidator handles validation of SelfRemediationPolicy resources
 type KubeMedicValidator struct {
 	Client  client.Client
 	decoder admission.Decoder
 }
 
-// Handle validates SelfRemediationPolicy resources
+// NewKubeMedicValidator creates a validator that decodes policies with the given scheme
+func NewKubeMedicValidator(c client.Client, scheme *runtime.Scheme) *KubeMedicValidator {
+	if c == nil {
+		panic("client cannot be nil")
+	}
+	if scheme == nil {
+		panic("scheme cannot be nil")
+	}
+	return &KubeMedicValidator{
+		Client:  c,
+		decoder: admission.NewDecoder(scheme),
+	}
+}
+
+// Handle validates SelfRemediationPolicy resources. Policies that fail validation or
+// cannot be decoded are denied.
 func (v *KubeMedicValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
 	log := log.FromContext(ctx).WithValues(
 		"webhook", "validator",
@@ -33,59 +62,45 @@ func (v *KubeMedicValidator) Handle(ctx context.Context, req admission.Request)
 
 	log.Info("Handling admission request")
 
-	// Basic validation
 	if req.Object.Raw == nil {
 		log.Error(nil, "No object provided")
-		return admission.Allowed("No object to validate")
+		return admission.Denied("no object to validate")
 	}
 
 	policy := &remediationv1alpha1.SelfRemediationPolicy{}
-	err := v.decoder.Decode(req, policy)
-	if err != nil {
+	if err := v.decoder.Decode(req, policy); err != nil {
 		log.Error(err, "Failed to decode admission request")
-		return admission.Allowed("Failed to decode, allowing by default")
+		return admission.Denied(fmt.Sprintf("failed to decode SelfRemediationPolicy: %v", err))
 	}
-
-	// Basic policy validation
-	if policy.Spec.Rules == nil {
-		log.Info("No rules specified in policy")
-		return admission.Allowed("No rules to validate")
+	// The namespace is not part of the object when it is created from a manifest without one
+	if policy.Namespace == "" {
+		policy.Namespace = req.Namespace
 	}
 
-	// Very basic validation - just check for obvious nil/empty values
-	for _, rule := range policy.Spec.Rules {
-		if rule.Name == "" {
-			log.Info("Rule missing name, allowing")
-			continue
+	// Only changes to the spec are validated. Updates of a policy being deleted, and updates
+	// that leave the spec alone, such as the controller adding or removing its finalizer, are
+	// allowed even when the target has since been deleted, excluded or protected; denying
+	// them would leave the policy stuck in Terminating.
+	if policy.DeletionTimestamp != nil {
+		return admission.Allowed("policy is being deleted")
+	}
+	if req.Operation == admissionv1.Update {
+		old := &remediationv1alpha1.SelfRemediationPolicy{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			log.Error(err, "Failed to decode previous policy")
+			return admission.Denied(fmt.Sprintf("failed to decode previous SelfRemediationPolicy: %v", err))
 		}
-
-		if len(rule.Actions) == 0 {
-			log.Info("Rule has no actions", "rule", rule.Name)
-			continue
-		}
-
-		for _, action := range rule.Actions {
-			if action.Type == "" {
-				log.Info("Action missing type", "rule", rule.Name)
-				continue
-			}
-
-			// Skip target validation for now
-			if action.Target.Kind == "" || action.Target.Name == "" {
-				log.Info("Action has incomplete target", "rule", rule.Name, "action", action.Type)
-				continue
-			}
+		if equality.Semantic.DeepEqual(old.Spec, policy.Spec) {
+			return admission.Allowed("policy spec is unchanged")
 		}
 	}
 
-	log.Info("Basic validation passed")
-	return admission.Allowed("Basic validation passed")
-}
+	if err := v.validatePolicy(ctx, policy); err != nil {
+		log.Info("Denying policy", "reason", err.Error())
+		return admission.Denied(err.Error())
+	}
 
-// InjectDecoder injects the decoder
-func (v *KubeMedicValidator) InjectDecoder(d *admission.Decoder) error {
-	v.decoder = *d
-	return nil
+	return admission.Allowed("policy is valid")
 }
 
 // validatePolicy validates all aspects of the policy
@@ -97,12 +112,32 @@ func (v *KubeMedicValidator) validatePolicy(ctx context.Context, policy *remedia
 
 	log.V(1).Info("Starting policy validation")
 
-	if err := v.validateNamespace(ctx, policy); err != nil {
+	settings, err := safety.Load(ctx, v.Client, policy.Namespace)
+	var invalid *safety.InvalidConfigError
+	if errors.As(err, &invalid) {
+		// The defaults stand in for the invalid settings; the mutating webhook warns about them
+		log.Error(err, "Ignoring invalid KubeMedic configuration")
+	} else if err != nil {
+		log.Error(err, "Failed to load KubeMedic configuration")
+		return err
+	}
+
+	if err := v.validateNamespace(ctx, policy, settings); err != nil {
 		log.Error(err, "Namespace validation failed")
 		return err
 	}
 
-	if err := v.validateResources(ctx, policy); err != nil {
+	if err := validateTargetRef(policy.Spec.TargetRef); err != nil {
+		log.Error(err, "Target validation failed")
+		return err
+	}
+
+	if err := validateConditions(policy); err != nil {
+		log.Error(err, "Condition validation failed")
+		return err
+	}
+
+	if err := v.validateResources(ctx, policy, settings); err != nil {
 		log.Error(err, "Resource validation failed")
 		return err
 	}
@@ -112,7 +147,7 @@ func (v *KubeMedicValidator) validatePolicy(ctx context.Context, policy *remedia
 		return err
 	}
 
-	if err := v.validateSafetyLimits(ctx, policy); err != nil {
+	if err := v.validateSafetyLimits(ctx, policy, settings.Limits); err != nil {
 		log.Error(err, "Safety limits validation failed")
 		return err
 	}
@@ -121,56 +156,88 @@ func (v *KubeMedicValidator) validatePolicy(ctx context.Context, policy *remedia
 	return nil
 }
 
-func (v *KubeMedicValidator) validateNamespace(ctx context.Context, policy *remediationv1alpha1.SelfRemediationPolicy) error {
-	// Check if namespace is in denied list
-	deniedNamespaces := []string{
-		"kube-system",
-		"kube-public",
-		"kube-node-lease",
-		"cert-manager",
-		"ingress-nginx",
+// validateNamespace checks the policy's namespace and every other namespace its targets
+// live in: none may be denied, outside the allowed namespaces or excluded from remediation
+func (v *KubeMedicValidator) validateNamespace(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	settings *safety.Settings,
+) error {
+	if err := v.checkNamespace(ctx, settings, policy.Namespace); err != nil {
+		return fmt.Errorf("%s: %w", namespacePath, err)
 	}
 
-	for _, ns := range deniedNamespaces {
-		if policy.Namespace == ns {
-			return fmt.Errorf("namespace %s is not allowed for remediation policies", ns)
+	checked := map[string]bool{policy.Namespace: true}
+	check := func(path *field.Path, namespace string) error {
+		if namespace == "" || checked[namespace] {
+			return nil
+		}
+		checked[namespace] = true
+		if err := v.checkNamespace(ctx, settings, namespace); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	}
+	if err := check(field.NewPath("spec", "targetRef", "namespace"), policy.Spec.TargetRef.Namespace); err != nil {
+		return err
+	}
+	for i, rule := range policy.Spec.Rules {
+		for j, action := range rule.Actions {
+			if err := check(actionPath(i, j).Child("target", "namespace"), action.Target.Namespace); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
+}
+
+// checkNamespace returns an error when remediation is not allowed in the namespace
+func (v *KubeMedicValidator) checkNamespace(ctx context.Context, settings *safety.Settings, name string) error {
+	// Check if namespace is in the denied or outside the allowed list
+	if err := settings.CheckNamespace(name); err != nil {
+		return err
+	}
 
 	// Check if namespace has required labels/annotations
 	namespace := &corev1.Namespace{}
-	if err := v.Client.Get(ctx, client.ObjectKey{Name: policy.Namespace}, namespace); err != nil {
-		return err
+	if err := v.Client.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+		return fmt.Errorf("failed to get namespace %s: %v", name, err)
 	}
 
-	if namespace.Labels["kubemedic.io/exclude"] == "true" {
-		return fmt.Errorf("namespace is excluded from remediation")
+	if namespace.Labels[safety.ExcludeLabel] == "true" {
+		return fmt.Errorf("namespace %s is excluded from remediation", name)
 	}
 
 	return nil
 }
 
-func (v *KubeMedicValidator) validateResources(ctx context.Context, policy *remediationv1alpha1.SelfRemediationPolicy) error {
-	allowedResources := map[string]bool{
-		"deployments":              true,
-		"statefulsets":             true,
-		"horizontalpodautoscalers": true,
-	}
+func (v *KubeMedicValidator) validateResources(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	settings *safety.Settings,
+) error {
+	for i, rule := range policy.Spec.Rules {
+		for j, action := range rule.Actions {
+			path := actionPath(i, j).Child("target")
+
+			// Restarts, rollbacks and resource updates without a target act on the pod from
+			// the policy's targetRef, or on the workload owning it
+			if action.Target.Kind == "" && action.Target.Name == "" && actsOnTargetPod(action.Type) {
+				continue
+			}
 
-	for _, rule := range policy.Spec.Rules {
-		for _, action := range rule.Actions {
 			// Check if Target is initialized
 			if action.Target.Kind == "" || action.Target.Name == "" {
-				return fmt.Errorf("action target must specify both kind and name")
+				return fmt.Errorf("%s: action target must specify both kind and name", path)
 			}
 
-			if !allowedResources[strings.ToLower(action.Target.Kind)] {
-				return fmt.Errorf("resource type %s is not allowed", action.Target.Kind)
+			if targetObject(action.Target.Kind) == nil {
+				return fmt.Errorf("%s: resource type %s is not allowed", path.Child("kind"), action.Target.Kind)
 			}
 
 			// Check if target resource exists and is not protected
-			if err := v.validateTargetResource(ctx, action.Target); err != nil {
-				return err
+			if err := v.validateTargetResource(ctx, targetInNamespace(action.Target, policy.Namespace), settings); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
 			}
 		}
 	}
@@ -181,13 +248,13 @@ func (v *KubeMedicValidator) validateResources(ctx context.Context, policy *reme
 func (v *KubeMedicValidator) validateActions(ctx context.Context, policy *remediationv1alpha1.SelfRemediationPolicy) error {
 	log := log.FromContext(ctx)
 
-	for _, rule := range policy.Spec.Rules {
+	for i, rule := range policy.Spec.Rules {
 		ruleLog := log.WithValues(
 			"rule_name", rule.Name,
 			"actions_count", len(rule.Actions),
 		)
 
-		for _, action := range rule.Actions {
+		for j, action := range rule.Actions {
 			actionLog := ruleLog.WithValues(
 				"action_type", action.Type,
 				"target_kind", action.Target.Kind,
@@ -198,12 +265,7 @@ func (v *KubeMedicValidator) validateActions(ctx context.Context, policy *remedi
 
 			if err := v.validateActionParams(action); err != nil {
 				actionLog.Error(err, "Action parameters validation failed")
-				return err
-			}
-
-			if err := v.validateTargetResource(ctx, action.Target); err != nil {
-				actionLog.Error(err, "Target resource validation failed")
-				return err
+				return fmt.Errorf("%s: %w", actionPath(i, j), err)
 			}
 
 			// For scaling actions, validate quota limits
@@ -221,40 +283,39 @@ func (v *KubeMedicValidator) validateActions(ctx context.Context, policy *remedi
 	return nil
 }
 
-func (v *KubeMedicValidator) validateSafetyLimits(ctx context.Context, policy *remediationv1alpha1.SelfRemediationPolicy) error {
-	// Global limits
-	maxScaleFactor := 2
-	maxDuration := 2 * time.Hour
-	minPods := 1
-
-	for _, rule := range policy.Spec.Rules {
-		for _, action := range rule.Actions {
+func (v *KubeMedicValidator) validateSafetyLimits(
+	ctx context.Context,
+	policy *remediationv1alpha1.SelfRemediationPolicy,
+	limits safety.Limits,
+) error {
+	for i, rule := range policy.Spec.Rules {
+		for j, action := range rule.Actions {
 			if action.ScalingParams != nil {
+				path := actionPath(i, j).Child("scalingParams")
+
 				// Check scale factor
 				if action.ScalingParams.TemporaryMaxReplicas != nil {
-					currentReplicas, err := v.getCurrentReplicas(ctx, action.Target)
+					replicasPath := path.Child("temporaryMaxReplicas")
+					currentReplicas, err := v.getCurrentReplicas(ctx, targetInNamespace(action.Target, policy.Namespace))
 					if err != nil {
-						return err
-					}
-
-					if *action.ScalingParams.TemporaryMaxReplicas > currentReplicas*int32(maxScaleFactor) {
-						return fmt.Errorf("scale factor exceeds maximum allowed (%d)", maxScaleFactor)
+						return fmt.Errorf("%s: %w", replicasPath, err)
 					}
 
-					if *action.ScalingParams.TemporaryMaxReplicas < int32(minPods) {
-						return fmt.Errorf("minimum pods cannot be less than %d", minPods)
+					if err := limits.CheckScaling(currentReplicas, *action.ScalingParams.TemporaryMaxReplicas); err != nil {
+						return fmt.Errorf("%s: %w", replicasPath, err)
 					}
 				}
 
 				// Check duration
 				if action.ScalingParams.ScalingDuration != "" {
+					durationPath := path.Child("scalingDuration")
 					duration, err := time.ParseDuration(action.ScalingParams.ScalingDuration)
 					if err != nil {
-						return fmt.Errorf("invalid duration format: %v", err)
+						return fmt.Errorf("%s: invalid duration format: %v", durationPath, err)
 					}
 
-					if duration > maxDuration {
-						return fmt.Errorf("scaling duration exceeds maximum allowed (%v)", maxDuration)
+					if err := limits.CheckDuration(duration); err != nil {
+						return fmt.Errorf("%s: %w", durationPath, err)
 					}
 				}
 			}
@@ -265,24 +326,39 @@ func (v *KubeMedicValidator) validateSafetyLimits(ctx context.Context, policy *r
 }
 
 func (v *KubeMedicValidator) validateQuotas(ctx context.Context, policy *remediationv1alpha1.SelfRemediationPolicy) error {
-	// Get namespace quotas
-	resourceQuota := &corev1.ResourceQuota{}
-	if err := v.Client.Get(ctx, client.ObjectKey{
-		Name:      "compute-resources",
-		Namespace: policy.Namespace,
-	}, resourceQuota); err != nil {
-		// If no quota exists, skip validation
-		return nil
+	// Get the quotas of each namespace scaled in
+	quotas := make(map[string]*corev1.ResourceQuotaList)
+	namespaceQuotas := func(namespace string) (*corev1.ResourceQuotaList, error) {
+		if list, ok := quotas[namespace]; ok {
+			return list, nil
+		}
+		list := &corev1.ResourceQuotaList{}
+		if err := v.Client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list resource quotas in namespace %s: %w", namespace, err)
+		}
+		quotas[namespace] = list
+		return list, nil
 	}
 
 	// Calculate potential resource usage
-	for _, rule := range policy.Spec.Rules {
-		for _, action := range rule.Actions {
+	for i, rule := range policy.Spec.Rules {
+		for j, action := range rule.Actions {
+			// Only workloads run pods that count against the quota
+			if action.Target.Kind != "Deployment" && action.Target.Kind != "StatefulSet" {
+				continue
+			}
 			if action.ScalingParams != nil && action.ScalingParams.TemporaryMaxReplicas != nil {
-				// Check if scaling would exceed quota
-				if err := v.checkQuotaLimits(ctx, action, resourceQuota); err != nil {
+				// Check if scaling would exceed the quotas of the target's namespace
+				action.Target = targetInNamespace(action.Target, policy.Namespace)
+				list, err := namespaceQuotas(action.Target.Namespace)
+				if err != nil {
 					return err
 				}
+				for k := range list.Items {
+					if err := v.checkQuotaLimits(ctx, action, &list.Items[k]); err != nil {
+						return fmt.Errorf("%s: %w", actionPath(i, j).Child("scalingParams", "temporaryMaxReplicas"), err)
+					}
+				}
 			}
 		}
 	}
@@ -290,12 +366,16 @@ func (v *KubeMedicValidator) validateQuotas(ctx context.Context, policy *remedia
 	return nil
 }
 
-func (v *KubeMedicValidator) validateTargetResource(ctx context.Context, target remediationv1alpha1.Target) error {
+func (v *KubeMedicValidator) validateTargetResource(
+	ctx context.Context,
+	target remediationv1alpha1.Target,
+	settings *safety.Settings,
+) error {
 	// Get the target resource
-	obj := &unstructured.Unstructured{}
-	obj.SetKind(target.Kind)
-	obj.SetNamespace(target.Namespace)
-	obj.SetName(target.Name)
+	obj := targetObject(target.Kind)
+	if obj == nil {
+		return fmt.Errorf("resource type %s is not allowed", target.Kind)
+	}
 
 	if err := v.Client.Get(ctx, client.ObjectKey{
 		Name:      target.Name,
@@ -304,14 +384,158 @@ func (v *KubeMedicValidator) validateTargetResource(ctx context.Context, target
 		return fmt.Errorf("target resource not found: %v", err)
 	}
 
-	// Check protection labels
-	if obj.GetLabels()["kubemedic.io/protected"] == "true" {
+	// Check protection labels and the configured protected selectors
+	if settings.IsProtected(obj.GetLabels()) {
 		return fmt.Errorf("target resource is protected from remediation")
 	}
 
 	return nil
 }
 
+// actsOnTargetPod reports whether actions of the type may leave out their target, acting
+// on the pod from the policy's targetRef instead
+func actsOnTargetPod(actionType remediationv1alpha1.ActionType) bool {
+	switch actionType {
+	case remediationv1alpha1.RestartPod, remediationv1alpha1.RollbackDeployment, remediationv1alpha1.UpdateResources:
+		return true
+	default:
+		return false
+	}
+}
+
+// targetObject returns an empty object of a target kind remediation actions support, or nil
+func targetObject(kind string) client.Object {
+	switch kind {
+	case "Deployment":
+		return &appsv1.Deployment{}
+	case "StatefulSet":
+		return &appsv1.StatefulSet{}
+	case "HorizontalPodAutoscaler", "HPA":
+		return &autoscalingv2.HorizontalPodAutoscaler{}
+	case "Pod":
+		return &corev1.Pod{}
+	default:
+		return nil
+	}
+}
+
+// targetInNamespace defaults the namespace of a target to the policy's namespace
+func targetInNamespace(target remediationv1alpha1.Target, namespace string) remediationv1alpha1.Target {
+	if target.Namespace == "" {
+		target.Namespace = namespace
+	}
+	return target
+}
+
+// validateTargetRef ensures the policy names a pod or a workload, or selects pods by label
+func validateTargetRef(ref remediationv1alpha1.TargetReference) error {
+	path := field.NewPath("spec", "targetRef")
+	if ref.Selector != nil {
+		if ref.Name != "" {
+			return fmt.Errorf("%s: name and selector are mutually exclusive", path)
+		}
+		if ref.Kind != "" && ref.Kind != "Pod" {
+			return fmt.Errorf("%s: a selector selects pods, kind must be Pod or empty", path.Child("kind"))
+		}
+		if len(ref.Selector.MatchLabels) == 0 && len(ref.Selector.MatchExpressions) == 0 {
+			return fmt.Errorf("%s: must not be empty", path.Child("selector"))
+		}
+		if _, err := metav1.LabelSelectorAsSelector(ref.Selector); err != nil {
+			return fmt.Errorf("%s: %w", path.Child("selector"), err)
+		}
+		return nil
+	}
+
+	switch ref.Kind {
+	case "", "Pod", "Deployment", "StatefulSet", "DaemonSet":
+	default:
+		return fmt.Errorf("%s: kind %s is not supported, must be Pod, Deployment, StatefulSet or DaemonSet",
+			path.Child("kind"), ref.Kind)
+	}
+	if ref.Name == "" {
+		return fmt.Errorf("%s: name or selector is required", path)
+	}
+	return nil
+}
+
+// validateConditions ensures every condition threshold, and the policy-wide CPU threshold
+// that stands in for rules without conditions, parses for its condition type, and that
+// every condition names a metrics source that can measure it
+func validateConditions(policy *remediationv1alpha1.SelfRemediationPolicy) error {
+	if policy.Spec.CPUThreshold != "" {
+		path := field.NewPath("spec", "cpuThreshold")
+		parsed, err := threshold.ForCondition(remediationv1alpha1.CPUUsage, policy.Spec.CPUThreshold)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if parsed.Bare {
+			return fmt.Errorf("%s: %w", path, bareThresholdError(remediationv1alpha1.CPUUsage, policy.Spec.CPUThreshold))
+		}
+	}
+
+	for i, rule := range policy.Spec.Rules {
+		for j, condition := range rule.Conditions {
+			path := field.NewPath("spec", "rules").Index(i).Child("conditions").Index(j)
+			parsed, err := threshold.ForCondition(condition.Type, condition.Threshold)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path.Child("threshold"), err)
+			}
+			resourceUsage := condition.Type == remediationv1alpha1.CPUUsage || condition.Type == remediationv1alpha1.MemoryUsage
+			if resourceUsage && parsed.Bare {
+				return fmt.Errorf("%s: %w", path.Child("threshold"), bareThresholdError(condition.Type, condition.Threshold))
+			}
+			if condition.RelativeTo != "" {
+				if !resourceUsage || !parsed.Percent {
+					return fmt.Errorf("%s: only applies to percentage thresholds of %s and %s conditions",
+						path.Child("relativeTo"), remediationv1alpha1.CPUUsage, remediationv1alpha1.MemoryUsage)
+				}
+			}
+			if _, err := metrics.ResolveSource(condition); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if condition.Window != "" {
+				if condition.Type != remediationv1alpha1.PodRestarts && condition.Type != remediationv1alpha1.OOMKilled {
+					return fmt.Errorf("%s: only applies to %s and %s conditions", path.Child("window"),
+						remediationv1alpha1.PodRestarts, remediationv1alpha1.OOMKilled)
+				}
+				window, err := time.ParseDuration(condition.Window)
+				if err != nil {
+					return fmt.Errorf("%s: invalid duration format: %v", path.Child("window"), err)
+				}
+				if window <= 0 {
+					return fmt.Errorf("%s: must be positive", path.Child("window"))
+				}
+			}
+			if condition.MetricSelector != nil {
+				if _, err := metav1.LabelSelectorAsSelector(condition.MetricSelector); err != nil {
+					return fmt.Errorf("%s: %w", path.Child("metricSelector"), err)
+				}
+			}
+			if condition.MinCount != nil && condition.Aggregation != remediationv1alpha1.AggregateCountOverThreshold {
+				return fmt.Errorf("%s: only applies to the %s aggregation",
+					path.Child("minCount"), remediationv1alpha1.AggregateCountOverThreshold)
+			}
+		}
+	}
+	return nil
+}
+
+// bareThresholdError rejects a resource usage threshold without a unit, which would be
+// read as cores or bytes although it was most likely meant as a percentage
+func bareThresholdError(conditionType remediationv1alpha1.ConditionType, raw string) error {
+	example := "500m"
+	if conditionType == remediationv1alpha1.MemoryUsage {
+		example = "512Mi"
+	}
+	return fmt.Errorf("threshold %q has no unit; write a percentage such as \"80%%\" or a quantity such as %q",
+		raw, example)
+}
+
+// actionPath returns the field path of an action, used to point denials at the offending field
+func actionPath(rule, action int) *field.Path {
+	return field.NewPath("spec", "rules").Index(rule).Child("actions").Index(action)
+}
+
 func (v *KubeMedicValidator) validateActionParams(action remediationv1alpha1.Action) error {
 	switch action.Type {
 	case remediationv1alpha1.ScaleUp, remediationv1alpha1.ScaleDown:
@@ -325,6 +549,10 @@ func (v *KubeMedicValidator) validateActionParams(action remediationv1alpha1.Act
 		if action.ScalingParams == nil || action.ScalingParams.TemporaryMaxReplicas == nil {
 			return fmt.Errorf("temporary max replicas required for HPA adjustment")
 		}
+	case remediationv1alpha1.UpdateResources:
+		if err := validateResourceParams(action.ResourceParams); err != nil {
+			return err
+		}
 	}
 
 	// Validate scaling duration if specified
@@ -334,51 +562,179 @@ func (v *KubeMedicValidator) validateActionParams(action remediationv1alpha1.Act
 		}
 	}
 
+	// Validate restart settings if specified
+	if params := action.RestartParams; params != nil {
+		switch params.Strategy {
+		case "", remediationv1alpha1.RestartEvict, remediationv1alpha1.RestartRollingRestart:
+		default:
+			return fmt.Errorf("invalid restart strategy %q: must be %s or %s",
+				params.Strategy, remediationv1alpha1.RestartEvict, remediationv1alpha1.RestartRollingRestart)
+		}
+		if params.MaxConcurrentRestarts != nil && *params.MaxConcurrentRestarts < 1 {
+			return fmt.Errorf("maxConcurrentRestarts must be at least 1")
+		}
+	}
+
+	// Validate rollback settings if specified
+	if action.RollbackParams != nil && action.RollbackParams.MinHealthyDuration != "" {
+		if _, err := time.ParseDuration(action.RollbackParams.MinHealthyDuration); err != nil {
+			return fmt.Errorf("invalid min healthy duration format: %v", err)
+		}
+	}
+
+	// Validate verification settings if specified
+	if params := action.Verification; params != nil {
+		window, err := time.ParseDuration(params.Window)
+		if err != nil {
+			return fmt.Errorf("invalid verification window format: %v", err)
+		}
+		if window <= 0 {
+			return fmt.Errorf("verification window must be positive")
+		}
+		if params.MinImprovement != "" {
+			improvement, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(params.MinImprovement), "%"), 64)
+			if err != nil || improvement <= 0 || improvement > 100 {
+				return fmt.Errorf("invalid minImprovement %q: must be a percentage between 0 and 100", params.MinImprovement)
+			}
+		}
+	}
+
+	// Validate gradual revert settings if specified
+	if params := action.ScalingParams; params != nil {
+		switch params.RevertStrategy {
+		case "", remediationv1alpha1.RevertImmediate, remediationv1alpha1.RevertGradual:
+		default:
+			return fmt.Errorf("invalid revert strategy %q: must be %s or %s",
+				params.RevertStrategy, remediationv1alpha1.RevertImmediate, remediationv1alpha1.RevertGradual)
+		}
+		if params.RevertStepSize != nil && *params.RevertStepSize < 1 {
+			return fmt.Errorf("revertStepSize must be at least 1")
+		}
+		if params.RevertStepInterval != "" {
+			interval, err := time.ParseDuration(params.RevertStepInterval)
+			if err != nil {
+				return fmt.Errorf("invalid revert step interval format: %v", err)
+			}
+			if interval <= 0 {
+				return fmt.Errorf("revertStepInterval must be positive")
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateResourceParams(params *remediationv1alpha1.ResourceParameters) error {
+	if params == nil || len(params.Adjustments) == 0 {
+		return fmt.Errorf("resource adjustments required for action type %s", remediationv1alpha1.UpdateResources)
+	}
+	for _, adjustment := range params.Adjustments {
+		if adjustment.Resource != string(corev1.ResourceCPU) && adjustment.Resource != string(corev1.ResourceMemory) {
+			return fmt.Errorf("invalid resource %q: must be cpu or memory", adjustment.Resource)
+		}
+		if adjustment.Request == "" && adjustment.Limit == "" && adjustment.Multiplier == "" {
+			return fmt.Errorf("adjustment of %s must set request, limit or multiplier", adjustment.Resource)
+		}
+		quantities := []struct{ field, value string }{
+			{"request", adjustment.Request},
+			{"limit", adjustment.Limit},
+			{"max", adjustment.Max},
+		}
+		for _, q := range quantities {
+			if q.value == "" {
+				continue
+			}
+			if _, err := resource.ParseQuantity(q.value); err != nil {
+				return fmt.Errorf("invalid %s %s quantity %q: %v", adjustment.Resource, q.field, q.value, err)
+			}
+		}
+		if adjustment.Multiplier != "" {
+			multiplier, err := strconv.ParseFloat(adjustment.Multiplier, 64)
+			if err != nil || multiplier <= 0 {
+				return fmt.Errorf("invalid %s multiplier %q: must be a positive number", adjustment.Resource, adjustment.Multiplier)
+			}
+		}
+	}
+	if params.Duration != "" {
+		if _, err := time.ParseDuration(params.Duration); err != nil {
+			return fmt.Errorf("invalid resource duration format: %v", err)
+		}
+	}
 	return nil
 }
 
 func (v *KubeMedicValidator) getCurrentReplicas(ctx context.Context, target remediationv1alpha1.Target) (int32, error) {
+	key := client.ObjectKey{Name: target.Name, Namespace: target.Namespace}
 	switch target.Kind {
 	case "Deployment":
 		deploy := &appsv1.Deployment{}
-		if err := v.Client.Get(ctx, client.ObjectKey{
-			Name:      target.Name,
-			Namespace: target.Namespace,
-		}, deploy); err != nil {
+		if err := v.Client.Get(ctx, key, deploy); err != nil {
 			return 0, err
 		}
-		return *deploy.Spec.Replicas, nil
+		return replicasOrDefault(deploy.Spec.Replicas), nil
 
 	case "StatefulSet":
 		sts := &appsv1.StatefulSet{}
-		if err := v.Client.Get(ctx, client.ObjectKey{
-			Name:      target.Name,
-			Namespace: target.Namespace,
-		}, sts); err != nil {
+		if err := v.Client.Get(ctx, key, sts); err != nil {
+			return 0, err
+		}
+		return replicasOrDefault(sts.Spec.Replicas), nil
+
+	case "HorizontalPodAutoscaler", "HPA":
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		if err := v.Client.Get(ctx, key, hpa); err != nil {
 			return 0, err
 		}
-		return *sts.Spec.Replicas, nil
+		return hpa.Spec.MaxReplicas, nil
 
 	default:
 		return 0, fmt.Errorf("unsupported resource type for replica count: %s", target.Kind)
 	}
 }
 
+// replicasOrDefault returns the replica count, which the API server defaults to 1 when unset
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// checkQuotaLimits checks that the pods added by scaling the action's target to its
+// temporary replicas fit in what the quota has left
 func (v *KubeMedicValidator) checkQuotaLimits(ctx context.Context, action remediationv1alpha1.Action, quota *corev1.ResourceQuota) error {
+	currentReplicas, err := v.getCurrentReplicas(ctx, action.Target)
+	if err != nil {
+		return err
+	}
+	added := int64(*action.ScalingParams.TemporaryMaxReplicas) - int64(currentReplicas)
+	if added <= 0 {
+		return nil
+	}
+
 	requirements, err := v.getResourceRequirements(ctx, action.Target)
 	if err != nil {
 		return err
 	}
+	requirements[corev1.ResourcePods] = *resource.NewQuantity(1, resource.DecimalSI)
 
 	// Calculate new resource usage
-	for resourceName, proposed := range requirements {
-		if hard, exists := quota.Status.Hard[resourceName]; exists {
-			// Convert to int64 for comparison
-			proposedValue := proposed.Value()
-			hardValue := hard.Value()
-
-			if proposedValue > hardValue {
-				return fmt.Errorf("action would exceed quota for %s", resourceName)
+	for name, perPod := range requirements {
+		delta := perPod.DeepCopy()
+		delta.Mul(added)
+		// Requests count against both the plain and the requests. quota entries
+		for _, quotaName := range []corev1.ResourceName{name, corev1.ResourceName("requests." + string(name))} {
+			hard, exists := quota.Status.Hard[quotaName]
+			if !exists {
+				continue
+			}
+			proposed := quota.Status.Used[quotaName].DeepCopy()
+			proposed.Add(delta)
+			if proposed.Cmp(hard) > 0 {
+				return fmt.Errorf("scaling %s %s/%s from %d to %d replicas would use %s of %s, exceeding quota %s (hard %s)",
+					action.Target.Kind, action.Target.Namespace, action.Target.Name,
+					currentReplicas, *action.ScalingParams.TemporaryMaxReplicas,
+					proposed.String(), quotaName, quota.Name, hard.String())
 			}
 		}
 	}
@@ -0,0 +1,289 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	remediationv1alpha1 "github.com/ikepcampbell/kubemedic/api/v1alpha1"
+	"github.com/ikepcampbell/kubemedic/pkg/safety"
+)
+
+// newTestValidator returns a validator reading the objects from a fake client
+func newTestValidator(t *testing.T, objects ...client.Object) *KubeMedicValidator {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := remediationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	return NewKubeMedicValidator(c, scheme)
+}
+
+// admissionRequest builds a request for the policy, and for updates the previous policy
+func admissionRequest(t *testing.T, operation admissionv1.Operation, policy, old *remediationv1alpha1.SelfRemediationPolicy) admission.Request {
+	t.Helper()
+	encode := func(policy *remediationv1alpha1.SelfRemediationPolicy) runtime.RawExtension {
+		policy.APIVersion = remediationv1alpha1.GroupVersion.String()
+		policy.Kind = "SelfRemediationPolicy"
+		raw, err := json.Marshal(policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runtime.RawExtension{Raw: raw}
+	}
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: operation,
+		Namespace: policy.Namespace,
+		Name:      policy.Name,
+		Object:    encode(policy),
+	}}
+	if old != nil {
+		req.OldObject = encode(old)
+	}
+	return req
+}
+
+// restartPolicy returns a policy restarting the pods of a Deployment that does not exist
+func restartPolicy() *remediationv1alpha1.SelfRemediationPolicy {
+	return &remediationv1alpha1.SelfRemediationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "restart", Namespace: "shop"},
+		Spec: remediationv1alpha1.SelfRemediationPolicySpec{
+			TargetRef: remediationv1alpha1.TargetReference{Kind: "Deployment", Name: "checkout", Namespace: "shop"},
+			Rules: []remediationv1alpha1.Rule{{
+				Name:       "restarts",
+				Conditions: []remediationv1alpha1.Condition{{Type: remediationv1alpha1.PodRestarts, Threshold: "3"}},
+				Actions: []remediationv1alpha1.Action{{
+					Type:   remediationv1alpha1.RestartPod,
+					Target: remediationv1alpha1.Target{Kind: "Pod", Name: "checkout-0", Namespace: "shop"},
+				}},
+			}},
+		},
+	}
+}
+
+func TestHandleUpdateWithoutSpecChange(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}
+	validator := newTestValidator(t, namespace)
+
+	old := restartPolicy()
+	policy := restartPolicy()
+	policy.Finalizers = []string{"remediation.kubemedic.io/reversion"}
+
+	// The action's target pod does not exist, so the spec itself no longer validates
+	if resp := validator.Handle(context.Background(), admissionRequest(t, admissionv1.Create, restartPolicy(), nil)); resp.Allowed {
+		t.Fatal("policy with a missing target was allowed on create")
+	}
+
+	resp := validator.Handle(context.Background(), admissionRequest(t, admissionv1.Update, policy, old))
+	if !resp.Allowed {
+		t.Errorf("finalizer update was denied: %s", resp.Result.Message)
+	}
+
+	changed := restartPolicy()
+	changed.Spec.CooldownPeriod = "10m"
+	resp = validator.Handle(context.Background(), admissionRequest(t, admissionv1.Update, changed, old))
+	if resp.Allowed {
+		t.Error("spec change with a missing target was allowed")
+	}
+}
+
+func TestHandleUpdateWhileDeleting(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "shop",
+		Labels: map[string]string{"kubemedic.io/exclude": "true"},
+	}}
+	validator := newTestValidator(t, namespace)
+
+	old := restartPolicy()
+	old.Finalizers = []string{"remediation.kubemedic.io/reversion"}
+	now := metav1.Now()
+	old.DeletionTimestamp = &now
+	policy := old.DeepCopy()
+	policy.Finalizers = nil
+
+	resp := validator.Handle(context.Background(), admissionRequest(t, admissionv1.Update, policy, old))
+	if !resp.Allowed {
+		t.Errorf("finalizer removal of a deleted policy was denied: %s", resp.Result.Message)
+	}
+}
+
+func TestValidateUpdateResourcesWithoutTarget(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}
+	validator := newTestValidator(t, namespace)
+
+	policy := &remediationv1alpha1.SelfRemediationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "memory", Namespace: "shop"},
+		Spec: remediationv1alpha1.SelfRemediationPolicySpec{
+			TargetRef: remediationv1alpha1.TargetReference{Kind: "Deployment", Name: "checkout", Namespace: "shop"},
+			Rules: []remediationv1alpha1.Rule{{
+				Name: "memory-pressure",
+				Conditions: []remediationv1alpha1.Condition{{
+					Type: remediationv1alpha1.MemoryUsage, Threshold: "90%",
+				}},
+				Actions: []remediationv1alpha1.Action{{
+					Type: remediationv1alpha1.UpdateResources,
+					ResourceParams: &remediationv1alpha1.ResourceParameters{
+						Adjustments: []remediationv1alpha1.ResourceAdjustment{{Resource: "memory", Multiplier: "1.5"}},
+					},
+				}},
+			}},
+		},
+	}
+
+	resp := validator.Handle(context.Background(), admissionRequest(t, admissionv1.Create, policy, nil))
+	if !resp.Allowed {
+		t.Errorf("UpdateResources without a target was denied: %s", resp.Result.Message)
+	}
+}
+
+func TestValidateScaleUpQuota(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}
+	replicas := int32(3)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("500m"),
+				}},
+			}}}},
+		},
+	}
+	quota := func(used string) *corev1.ResourceQuota {
+		return &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "shop"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4")},
+				Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse(used)},
+			},
+		}
+	}
+	scaleTo := int32(6)
+	policy := &remediationv1alpha1.SelfRemediationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "scale", Namespace: "shop"},
+		Spec: remediationv1alpha1.SelfRemediationPolicySpec{
+			TargetRef: remediationv1alpha1.TargetReference{Kind: "Deployment", Name: "checkout", Namespace: "shop"},
+			Rules: []remediationv1alpha1.Rule{{
+				Name:       "cpu",
+				Conditions: []remediationv1alpha1.Condition{{Type: remediationv1alpha1.CPUUsage, Threshold: "80%"}},
+				Actions: []remediationv1alpha1.Action{{
+					Type:          remediationv1alpha1.ScaleUp,
+					Target:        remediationv1alpha1.Target{Kind: "Deployment", Name: "checkout", Namespace: "shop"},
+					ScalingParams: &remediationv1alpha1.ScalingParameters{TemporaryMaxReplicas: &scaleTo},
+				}},
+			}},
+		},
+	}
+
+	// Three more pods request 1.5 CPUs
+	validator := newTestValidator(t, namespace, deployment, quota("2500m"))
+	resp := validator.Handle(context.Background(), admissionRequest(t, admissionv1.Create, policy.DeepCopy(), nil))
+	if !resp.Allowed {
+		t.Errorf("scale up within the quota was denied: %s", resp.Result.Message)
+	}
+
+	validator = newTestValidator(t, namespace, deployment, quota("3"))
+	resp = validator.Handle(context.Background(), admissionRequest(t, admissionv1.Create, policy.DeepCopy(), nil))
+	if resp.Allowed || !strings.Contains(resp.Result.Message, "exceeding quota compute") {
+		t.Errorf("scale up exceeding the quota was not denied by the quota: allowed=%v %s", resp.Allowed, resp.Result.Message)
+	}
+}
+
+func TestValidateConditionsBareThreshold(t *testing.T) {
+	tests := []struct {
+		name          string
+		conditionType remediationv1alpha1.ConditionType
+		threshold     string
+		wantErr       bool
+	}{
+		{name: "CPU percentage", conditionType: remediationv1alpha1.CPUUsage, threshold: "80%"},
+		{name: "CPU quantity", conditionType: remediationv1alpha1.CPUUsage, threshold: ">=1500m"},
+		{name: "bare CPU", conditionType: remediationv1alpha1.CPUUsage, threshold: "80", wantErr: true},
+		{name: "memory quantity", conditionType: remediationv1alpha1.MemoryUsage, threshold: "512Mi"},
+		{name: "bare memory", conditionType: remediationv1alpha1.MemoryUsage, threshold: "90", wantErr: true},
+		{name: "bare memory range", conditionType: remediationv1alpha1.MemoryUsage, threshold: "1Mi..4194304", wantErr: true},
+		{name: "restart count", conditionType: remediationv1alpha1.PodRestarts, threshold: "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := restartPolicy()
+			policy.Spec.Rules[0].Conditions = []remediationv1alpha1.Condition{{Type: tt.conditionType, Threshold: tt.threshold}}
+			err := validateConditions(policy)
+			if tt.wantErr != (err != nil) {
+				t.Errorf("validateConditions(%s %q) = %v, want error %v", tt.conditionType, tt.threshold, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTargetNamespaces(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace *corev1.Namespace
+		wantErr   string
+	}{
+		{
+			name:      "allowed namespace",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "billing"}},
+		},
+		{
+			name: "excluded namespace",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "billing",
+				Labels: map[string]string{safety.ExcludeLabel: "true"},
+			}},
+			wantErr: "namespace billing is excluded from remediation",
+		},
+		{
+			name:    "missing namespace",
+			wantErr: "failed to get namespace billing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "checkout-0", Namespace: "billing"}},
+			}
+			if tt.namespace != nil {
+				objects = append(objects, tt.namespace)
+			}
+			policy := restartPolicy()
+			policy.Spec.Rules[0].Actions[0].Target.Namespace = "billing"
+
+			resp := newTestValidator(t, objects...).Handle(context.Background(), admissionRequest(t, admissionv1.Create, policy, nil))
+			if tt.wantErr == "" {
+				if !resp.Allowed {
+					t.Errorf("target in namespace billing was denied: %s", resp.Result.Message)
+				}
+				return
+			}
+			if resp.Allowed || !strings.Contains(resp.Result.Message, tt.wantErr) {
+				t.Errorf("target in namespace billing: allowed=%v %q, want denial %q", resp.Allowed, resp.Result.Message, tt.wantErr)
+			}
+			if !strings.Contains(resp.Result.Message, "spec.rules[0].actions[0].target.namespace") {
+				t.Errorf("denial %q does not name the target namespace field", resp.Result.Message)
+			}
+		})
+	}
+}